@@ -5,6 +5,9 @@
 package xdr_test
 
 import (
+	"io"
+	"unsafe"
+
 	"dario.cat/xdr"
 )
 
@@ -66,18 +69,17 @@ struct XDRBenchStruct {
 // XDRSize returns the XDR encoded form's size.
 func (o XDRBenchStruct) XDRSize() int {
 	return 8 + 4 + 4 + 4 +
-		4 + len(o.Bs0) + xdr.Padding(len(o.Bs0)) +
-		4 + len(o.Bs1) + xdr.Padding(len(o.Bs1)) +
+		xdr.BytesSize(len(o.Bs0)) +
+		xdr.BytesSize(len(o.Bs1)) +
 		4 + len(o.Is0)*4 +
-		4 + len(o.S0) + xdr.Padding(len(o.S0)) +
-		4 + len(o.S1) + xdr.Padding(len(o.S1))
+		xdr.StringSize(o.S0) +
+		xdr.StringSize(o.S1)
 }
 
 // MarshalXDR returns the XDR encoding.
 func (o XDRBenchStruct) MarshalXDR() ([]byte, error) {
-	buf := make([]byte, o.XDRSize())
-	m := &xdr.Marshaller{Data: buf}
-	return buf, o.MarshalXDRInto(m)
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
 }
 
 // MustMarshalXDR returns the XDR encoding. MustMarshalXDR
@@ -113,37 +115,147 @@ func (o XDRBenchStruct) MarshalXDRInto(m *xdr.Marshaller) error {
 	return m.Error
 }
 
+// EncodeXDR writes the struct to the provided Encoder.
+func (o XDRBenchStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint64(o.I1)
+	e.EncodeUint32(o.I2)
+	e.EncodeUint16(o.I3)
+	e.EncodeUint8(o.I4)
+	if l := len(o.Bs0); l > 128 {
+		return xdr.ElementSizeExceeded("Bs0", l, 128)
+	}
+	e.EncodeBytes(o.Bs0)
+	e.EncodeBytes(o.Bs1)
+	e.EncodeUint32(uint32(len(o.Is0)))
+	for i := range o.Is0 {
+		e.EncodeUint32(uint32(o.Is0[i]))
+	}
+	if l := len(o.S0); l > 128 {
+		return xdr.ElementSizeExceeded("S0", l, 128)
+	}
+	e.EncodeString(o.S0)
+	e.EncodeString(o.S1)
+	return e.Err()
+}
+
 // UnmarshalXDR parses the XDR-encoded data and stores the result in the
 // struct.
 func (o *XDRBenchStruct) UnmarshalXDR(bs []byte) error {
-	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *XDRBenchStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
 	return o.UnmarshalXDRFrom(u)
 }
 
 // UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
 func (o *XDRBenchStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
 	o.I1 = u.UnmarshalUint64()
 	o.I2 = u.UnmarshalUint32()
 	o.I3 = u.UnmarshalUint16()
 	o.I4 = u.UnmarshalUint8()
+	if _, err := xdr.CheckLength("Bs0", u.PeekUint32(), 128); err != nil {
+		return u.Fail(err)
+	}
 	o.Bs0 = u.UnmarshalBytesMax(128)
 	o.Bs1 = u.UnmarshalBytes()
-	_Is0Size := int(u.UnmarshalUint32())
-	if _Is0Size < 0 {
-		return xdr.ElementSizeExceeded("Is0", _Is0Size, 0)
-	} else if _Is0Size == 0 {
+	_Is0Size := u.UnmarshalLength("Is0", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _Is0Size == 0 {
 		o.Is0 = nil
 	} else {
-		if _Is0Size <= len(o.Is0) {
+		if !u.Require(_Is0Size, 4) {
+			return u.Error
+		}
+		if _Is0Size <= cap(o.Is0) {
 			o.Is0 = o.Is0[:_Is0Size]
 		} else {
-			o.Is0 = make([]int32, _Is0Size)
+			_Is0Cap := u.Capacity("XDRBenchStruct.Is0", _Is0Size)
+			if !u.Alloc(_Is0Cap * int(unsafe.Sizeof(o.Is0[0]))) {
+				return u.Error
+			}
+			o.Is0 = make([]int32, _Is0Size, _Is0Cap)
 		}
 		for i := range o.Is0 {
 			o.Is0[i] = int32(u.UnmarshalUint32())
 		}
 	}
+	if _, err := xdr.CheckLength("S0", u.PeekUint32(), 128); err != nil {
+		return u.Fail(err)
+	}
 	o.S0 = u.UnmarshalStringMax(128)
 	o.S1 = u.UnmarshalString()
 	return u.Error
 }
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o XDRBenchStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o XDRBenchStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *XDRBenchStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o XDRBenchStruct) Equal(p XDRBenchStruct) bool {
+	if o.I1 != p.I1 {
+		return false
+	}
+	if o.I2 != p.I2 {
+		return false
+	}
+	if o.I3 != p.I3 {
+		return false
+	}
+	if o.I4 != p.I4 {
+		return false
+	}
+	if string(o.Bs0) != string(p.Bs0) {
+		return false
+	}
+	if string(o.Bs1) != string(p.Bs1) {
+		return false
+	}
+	if len(o.Is0) != len(p.Is0) {
+		return false
+	}
+	for i := range o.Is0 {
+		if o.Is0[i] != p.Is0[i] {
+			return false
+		}
+	}
+	if o.S0 != p.S0 {
+		return false
+	}
+	if o.S1 != p.S1 {
+		return false
+	}
+	return true
+}
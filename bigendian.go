@@ -0,0 +1,59 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+// The functions below store and load integers in big-endian, network byte
+// order, the layout XDR uses for them. They are what the Marshaller,
+// Unmarshaller, Encoder and Decoder use, and are exported for embedding raw
+// integers in opaque data without going through them. Like those of
+// encoding/binary, they panic if the slice is too short.
+
+// PutUint16BE stores v in the first two bytes of dst.
+func PutUint16BE(dst []byte, v uint16) {
+	_ = dst[1] // bounds check hint to the compiler
+	dst[0] = byte(v >> 8)
+	dst[1] = byte(v)
+}
+
+// Uint16BE returns the uint16 stored in the first two bytes of src.
+func Uint16BE(src []byte) uint16 {
+	_ = src[1] // bounds check hint to the compiler
+	return uint16(src[1]) | uint16(src[0])<<8
+}
+
+// PutUint32BE stores v in the first four bytes of dst.
+func PutUint32BE(dst []byte, v uint32) {
+	_ = dst[3] // bounds check hint to the compiler
+	dst[0] = byte(v >> 24)
+	dst[1] = byte(v >> 16)
+	dst[2] = byte(v >> 8)
+	dst[3] = byte(v)
+}
+
+// Uint32BE returns the uint32 stored in the first four bytes of src.
+func Uint32BE(src []byte) uint32 {
+	_ = src[3] // bounds check hint to the compiler
+	return uint32(src[3]) | uint32(src[2])<<8 | uint32(src[1])<<16 | uint32(src[0])<<24
+}
+
+// PutUint64BE stores v in the first eight bytes of dst.
+func PutUint64BE(dst []byte, v uint64) {
+	_ = dst[7] // bounds check hint to the compiler
+	dst[0] = byte(v >> 56)
+	dst[1] = byte(v >> 48)
+	dst[2] = byte(v >> 40)
+	dst[3] = byte(v >> 32)
+	dst[4] = byte(v >> 24)
+	dst[5] = byte(v >> 16)
+	dst[6] = byte(v >> 8)
+	dst[7] = byte(v)
+}
+
+// Uint64BE returns the uint64 stored in the first eight bytes of src.
+func Uint64BE(src []byte) uint64 {
+	_ = src[7] // bounds check hint to the compiler
+	return uint64(src[7]) | uint64(src[6])<<8 | uint64(src[5])<<16 | uint64(src[4])<<24 |
+		uint64(src[3])<<32 | uint64(src[2])<<40 | uint64(src[1])<<48 | uint64(src[0])<<56
+}
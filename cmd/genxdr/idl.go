@@ -0,0 +1,662 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file translates schemas written in the XDR language of RFC 4506 into
+// the annotated Go declarations genxdr reads, so that the rest of the
+// generator handles both kinds of input alike. The supported subset is
+// constants, enums, structs, unions without default arms, typedefs, opaque
+// data, strings, variable-length arrays, fixed-length opaque data and
+// optional data. Fixed-length arrays of types other than opaque are not
+// supported, as genxdr only encodes arrays of bytes. RPC program definitions
+// are skipped.
+
+type idlKind int
+
+const (
+	idlScalar   idlKind = iota // T name
+	idlFixed                   // T name[n]
+	idlVar                     // T name<n>
+	idlOptional                // T *name
+	idlVoid                    // void
+)
+
+// idlDecl is a declaration, as found in structs, unions and typedefs.
+type idlDecl struct {
+	name string
+	typ  string // i.e. "unsigned int", "opaque" or a type name
+	kind idlKind
+	size string // size of a fixed array, or max size of a variable one
+}
+
+type idlConst struct {
+	name  string
+	value string
+}
+
+type idlEnum struct {
+	name   string
+	values []idlConst // values are blank where omitted
+}
+
+type idlStruct struct {
+	name   string
+	fields []idlDecl
+}
+
+type idlArm struct {
+	cases []string
+	decl  idlDecl
+}
+
+type idlUnion struct {
+	name string
+	disc idlDecl
+	arms []idlArm
+}
+
+// idlSpec holds the definitions of an XDR language file, in order.
+type idlSpec struct {
+	defs     []interface{}
+	consts   map[string]string
+	typedefs map[string]idlDecl
+	types    map[string]bool // enums, structs and unions
+}
+
+// idlError is raised by the parser, and recovered by translateIDL.
+type idlError struct {
+	err error
+}
+
+type idlParser struct {
+	toks  []string
+	lines []int
+	pos   int
+}
+
+// translateIDL translates the XDR language source into Go declarations,
+// without a package clause.
+func translateIDL(src []byte) (decls []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ie, ok := r.(idlError)
+			if !ok {
+				panic(r)
+			}
+			err = ie.err
+		}
+	}()
+
+	p := &idlParser{}
+	p.lex(src)
+	spec := p.spec()
+
+	var buf bytes.Buffer
+	spec.emit(&buf)
+	return buf.Bytes(), nil
+}
+
+func (p *idlParser) fail(format string, args ...interface{}) {
+	line := 0
+	if p.pos < len(p.lines) {
+		line = p.lines[p.pos]
+	} else if len(p.lines) > 0 {
+		line = p.lines[len(p.lines)-1]
+	}
+	panic(idlError{fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))})
+}
+
+// lex splits src into tokens, dropping whitespace, comments and the lines
+// starting with % that rpcgen passes through.
+func (p *idlParser) lex(src []byte) {
+	s := string(src)
+	line := 1
+	bol := true
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			line++
+			bol = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '%' && bol:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				p.lines = append(p.lines, line)
+				p.pos = len(p.lines) - 1
+				p.fail("unterminated comment")
+			}
+			line += strings.Count(s[i:i+2+end], "\n")
+			i += end + 4
+			continue
+		case strings.HasPrefix(s[i:], "//"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			continue
+		}
+		bol = false
+
+		j := i + 1
+		switch {
+		case c == '_' || unicode.IsLetter(rune(c)):
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+		case unicode.IsDigit(rune(c)) || c == '-' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1])):
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || unicode.IsLetter(rune(s[j]))) {
+				j++
+			}
+		case strings.IndexByte("{}[]<>();,=:*", c) >= 0:
+		default:
+			p.lines = append(p.lines, line)
+			p.pos = len(p.lines) - 1
+			p.fail("unexpected character %q", c)
+		}
+		p.toks = append(p.toks, s[i:j])
+		p.lines = append(p.lines, line)
+		i = j
+	}
+}
+
+func (p *idlParser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+func (p *idlParser) next() string {
+	if p.pos >= len(p.toks) {
+		p.fail("unexpected end of file")
+	}
+	p.pos++
+	return p.toks[p.pos-1]
+}
+
+func (p *idlParser) expect(tok string) {
+	if t := p.next(); t != tok {
+		p.pos--
+		p.fail("expected %q, found %q", tok, t)
+	}
+}
+
+func (p *idlParser) ident() string {
+	t := p.next()
+	if t == "" || !(t[0] == '_' || unicode.IsLetter(rune(t[0]))) {
+		p.pos--
+		p.fail("expected identifier, found %q", t)
+	}
+	return t
+}
+
+// value parses a constant or a constant's name.
+func (p *idlParser) value() string {
+	t := p.next()
+	if t == "" || strings.IndexByte("{}[]<>();,=:*", t[0]) >= 0 {
+		p.pos--
+		p.fail("expected value, found %q", t)
+	}
+	return t
+}
+
+func (p *idlParser) spec() *idlSpec {
+	spec := &idlSpec{
+		consts:   make(map[string]string),
+		typedefs: make(map[string]idlDecl),
+		types:    make(map[string]bool),
+	}
+
+	for p.pos < len(p.toks) {
+		switch t := p.next(); t {
+		case "const":
+			c := idlConst{name: p.ident()}
+			p.expect("=")
+			c.value = p.value()
+			p.expect(";")
+			spec.consts[c.name] = c.value
+			spec.defs = append(spec.defs, c)
+
+		case "enum", "struct", "union":
+			name := p.ident()
+			spec.defs = append(spec.defs, p.body(t, name))
+			spec.types[name] = true
+			p.expect(";")
+
+		case "typedef":
+			if k := p.peek(); (k == "enum" || k == "struct" || k == "union") && p.pos+1 < len(p.toks) && p.toks[p.pos+1] == "{" {
+				p.next()
+				def := p.body(k, "")
+				name := p.ident()
+				switch d := def.(type) {
+				case idlEnum:
+					d.name = name
+					def = d
+				case idlStruct:
+					d.name = name
+					def = d
+				case idlUnion:
+					d.name = name
+					def = d
+				}
+				spec.defs = append(spec.defs, def)
+				spec.types[name] = true
+				p.expect(";")
+				continue
+			}
+			d := p.decl()
+			if d.kind == idlVoid {
+				p.fail("void typedef")
+			}
+			p.expect(";")
+			spec.typedefs[d.name] = d
+			spec.defs = append(spec.defs, d)
+
+		case "program":
+			p.skipProgram()
+
+		default:
+			p.pos--
+			p.fail("unexpected %q", t)
+		}
+	}
+
+	return spec
+}
+
+// body parses the body of an enum, struct or union definition.
+func (p *idlParser) body(kind, name string) interface{} {
+	switch kind {
+	case "enum":
+		e := idlEnum{name: name}
+		p.expect("{")
+		for {
+			c := idlConst{name: p.ident()}
+			if p.peek() == "=" {
+				p.next()
+				c.value = p.value()
+			}
+			e.values = append(e.values, c)
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		p.expect("}")
+		return e
+
+	case "struct":
+		s := idlStruct{name: name}
+		p.expect("{")
+		for p.peek() != "}" {
+			d := p.decl()
+			if d.kind == idlVoid {
+				p.fail("void struct member")
+			}
+			p.expect(";")
+			s.fields = append(s.fields, d)
+		}
+		p.expect("}")
+		return s
+
+	default:
+		u := idlUnion{name: name}
+		p.expect("switch")
+		p.expect("(")
+		u.disc = p.decl()
+		if u.disc.kind != idlScalar {
+			p.fail("invalid union discriminant")
+		}
+		p.expect(")")
+		p.expect("{")
+		for p.peek() != "}" {
+			if p.peek() == "default" {
+				p.fail("default union arms are not supported")
+			}
+			var arm idlArm
+			for p.peek() == "case" {
+				p.next()
+				arm.cases = append(arm.cases, p.value())
+				p.expect(":")
+			}
+			if len(arm.cases) == 0 {
+				p.fail("expected \"case\", found %q", p.peek())
+			}
+			arm.decl = p.decl()
+			p.expect(";")
+			u.arms = append(u.arms, arm)
+		}
+		p.expect("}")
+		return u
+	}
+}
+
+// typeSpec parses a type specifier, returning the XDR type name.
+func (p *idlParser) typeSpec() string {
+	switch t := p.next(); t {
+	case "unsigned":
+		switch p.peek() {
+		case "int", "hyper":
+			return "unsigned " + p.next()
+		}
+		return "unsigned int"
+	case "enum", "struct", "union":
+		if p.peek() == "{" {
+			p.fail("nested %s definitions are not supported", t)
+		}
+		return p.ident()
+	default:
+		p.pos--
+		return p.ident()
+	}
+}
+
+// decl parses a declaration.
+func (p *idlParser) decl() idlDecl {
+	if p.peek() == "void" {
+		p.next()
+		return idlDecl{kind: idlVoid}
+	}
+
+	d := idlDecl{typ: p.typeSpec()}
+	if p.peek() == "*" {
+		p.next()
+		d.name = p.ident()
+		d.kind = idlOptional
+		return d
+	}
+
+	d.name = p.ident()
+	switch p.peek() {
+	case "[":
+		p.next()
+		d.kind = idlFixed
+		d.size = p.value()
+		p.expect("]")
+	case "<":
+		p.next()
+		d.kind = idlVar
+		if p.peek() != ">" {
+			d.size = p.value()
+		}
+		p.expect(">")
+	default:
+		if d.typ == "string" || d.typ == "opaque" {
+			p.fail("%s %s must have a size", d.typ, d.name)
+		}
+	}
+	return d
+}
+
+// skipProgram skips an RPC program definition.
+func (p *idlParser) skipProgram() {
+	p.ident()
+	p.expect("{")
+	for depth := 1; depth > 0; {
+		switch p.next() {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+	}
+	p.expect("=")
+	p.value()
+	p.expect(";")
+}
+
+var idlBasicTypes = map[string]string{
+	"int":            "int32",
+	"unsigned int":   "uint32",
+	"hyper":          "int64",
+	"unsigned hyper": "uint64",
+	"bool":           "bool",
+	"float":          "float32",
+	"double":         "float64",
+}
+
+// idlGoName turns an XDR identifier into an exported Go one, i.e.
+// "file_info" into "FileInfo" and "STATUS_OK" into "StatusOk".
+func idlGoName(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		if strings.ToUpper(part) == part {
+			part = strings.ToLower(part)
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	n := b.String()
+	if n == "" || unicode.IsDigit(rune(n[0])) {
+		n = "X" + n
+	}
+	return n
+}
+
+// fail reports an error in the definitions, found while emitting them.
+func (spec *idlSpec) fail(format string, args ...interface{}) {
+	panic(idlError{fmt.Errorf(format, args...)})
+}
+
+// valueExpr returns the Go expression for a constant or a constant's name.
+func (spec *idlSpec) valueExpr(v string) string {
+	switch v {
+	case "TRUE":
+		return "true"
+	case "FALSE":
+		return "false"
+	}
+	if unicode.IsDigit(rune(v[0])) || v[0] == '-' {
+		return v
+	}
+	return idlGoName(v)
+}
+
+// number returns the numeric value of a size, following constant names.
+func (spec *idlSpec) number(v string) int {
+	if v == "" {
+		spec.fail("missing size")
+	}
+	seen := make(map[string]bool)
+	for v != "" && !unicode.IsDigit(rune(v[0])) && v[0] != '-' {
+		if seen[v] {
+			spec.fail("constant %s is defined in terms of itself", v)
+		}
+		seen[v] = true
+		c, ok := spec.consts[v]
+		if !ok {
+			spec.fail("undefined constant %s", v)
+		}
+		v = c
+	}
+	n, err := strconv.ParseInt(v, 0, 32)
+	if err != nil || n < 0 {
+		spec.fail("invalid size %s", v)
+	}
+	return int(n)
+}
+
+// resolve follows typedefs of scalar declarations, returning the declaration
+// a type name stands for.
+func (spec *idlSpec) resolve(typ string) idlDecl {
+	d := idlDecl{typ: typ}
+	for i := 0; i <= len(spec.typedefs); i++ {
+		td, ok := spec.typedefs[d.typ]
+		if !ok {
+			return d
+		}
+		if td.kind != idlScalar {
+			return td
+		}
+		d.typ = td.typ
+	}
+	spec.fail("typedef loop at %s", typ)
+	return d
+}
+
+// scalarType returns the Go type of a basic or named XDR type.
+func (spec *idlSpec) scalarType(typ string) string {
+	if t, ok := idlBasicTypes[typ]; ok {
+		return t
+	}
+	if spec.types[typ] {
+		return idlGoName(typ)
+	}
+	if typ == "string" || typ == "opaque" {
+		spec.fail("%s must have a size", typ)
+	}
+	spec.fail("unsupported or undefined type %s", typ)
+	return ""
+}
+
+// fieldType returns the Go type for a declaration, and the max sizes for
+// its annotation.
+func (spec *idlSpec) fieldType(d idlDecl) (typ string, max []int) {
+	size := 0
+	if d.size != "" {
+		size = spec.number(d.size)
+	}
+
+	switch d.kind {
+	case idlScalar:
+		r := spec.resolve(d.typ)
+		if r.kind != idlScalar {
+			return spec.fieldType(r)
+		}
+		return spec.scalarType(r.typ), nil
+
+	case idlFixed:
+		if d.typ != "opaque" {
+			spec.fail("fixed-length arrays of %s are not supported, only of opaque", d.typ)
+		}
+		return fmt.Sprintf("[%d]byte", size), nil
+
+	case idlVar:
+		switch d.typ {
+		case "opaque":
+			return "[]byte", []int{size}
+		case "string":
+			return "string", []int{size}
+		}
+		r := spec.resolve(d.typ)
+		if r.kind == idlScalar {
+			return "[]" + spec.scalarType(r.typ), []int{size}
+		}
+		if r.kind == idlVar && (r.typ == "string" || r.typ == "opaque") {
+			et, emax := spec.fieldType(r)
+			return "[]" + et, []int{size, emax[0]}
+		}
+		spec.fail("variable-length arrays of %s are not supported", d.typ)
+
+	case idlOptional:
+		r := spec.resolve(d.typ)
+		if r.kind == idlScalar {
+			return "*" + spec.scalarType(r.typ), nil
+		}
+		if r.kind == idlVar && r.typ == "string" {
+			rsize := 0
+			if r.size != "" {
+				rsize = spec.number(r.size)
+			}
+			return "*string", []int{rsize}
+		}
+		spec.fail("optional %s is not supported", d.typ)
+	}
+
+	spec.fail("unsupported declaration of %s", d.name)
+	return "", nil
+}
+
+// emit writes the Go declarations for the definitions.
+func (spec *idlSpec) emit(buf *bytes.Buffer) {
+	for _, def := range spec.defs {
+		switch d := def.(type) {
+		case idlConst:
+			fmt.Fprintf(buf, "const %s = %s\n\n", idlGoName(d.name), spec.valueExpr(d.value))
+
+		case idlDecl:
+			typ, _ := spec.fieldType(idlDecl{name: d.name, typ: d.name})
+			fmt.Fprintf(buf, "// %s is the XDR typedef %s.\ntype %s = %s\n\n", idlGoName(d.name), d.name, idlGoName(d.name), typ)
+
+		case idlEnum:
+			name := idlGoName(d.name)
+			fmt.Fprintf(buf, "// %s is the XDR enum %s.\n//\n//xdr:enum\ntype %s int32\n\nconst (\n", name, d.name, name)
+			prev := ""
+			for _, c := range d.values {
+				v := c.value
+				switch {
+				case v != "":
+					v = spec.valueExpr(v)
+				case prev != "":
+					v = prev + " + 1"
+				default:
+					v = "0"
+				}
+				fmt.Fprintf(buf, "\t%s %s = %s\n", idlGoName(c.name), name, v)
+				prev = idlGoName(c.name)
+			}
+			fmt.Fprintf(buf, ")\n\n")
+
+		case idlStruct:
+			name := idlGoName(d.name)
+			fmt.Fprintf(buf, "// %s is the XDR struct %s.\ntype %s struct {\n", name, d.name, name)
+			for _, f := range d.fields {
+				typ, max := spec.fieldType(f)
+				fmt.Fprintf(buf, "\t%s %s%s\n", idlGoName(f.name), typ, idlMaxComment(max))
+			}
+			fmt.Fprintf(buf, "}\n\n")
+
+		case idlUnion:
+			name := idlGoName(d.name)
+			var arms []string
+			for _, a := range d.arms {
+				typ := "void"
+				if a.decl.kind != idlVoid {
+					r := spec.resolve(a.decl.typ)
+					if a.decl.kind != idlScalar || r.kind != idlScalar || !spec.types[r.typ] {
+						spec.fail("arm %s of union %s must be a struct, union or enum", a.decl.name, d.name)
+					}
+					typ = idlGoName(r.typ)
+				}
+				for _, c := range a.cases {
+					arms = append(arms, spec.valueExpr(c)+"="+typ)
+				}
+			}
+			disc, _ := spec.fieldType(d.disc)
+			fmt.Fprintf(buf, "// %s is the XDR union %s.\n//\n//xdr:union\ntype %s struct {\n", name, d.name, name)
+			fmt.Fprintf(buf, "\t%s %s\n\tValue interface{} // arms: %s\n}\n\n", idlGoName(d.disc.name), disc, strings.Join(arms, ", "))
+		}
+	}
+}
+
+// idlMaxComment returns the genxdr annotation for the max sizes, if any.
+func idlMaxComment(max []int) string {
+	switch {
+	case len(max) == 2 && max[1] > 0:
+		return fmt.Sprintf(" // max:%d, %d", max[0], max[1])
+	case len(max) > 0 && max[0] > 0:
+		return fmt.Sprintf(" // max:%d", max[0])
+	}
+	return ""
+}
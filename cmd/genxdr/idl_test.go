@@ -0,0 +1,33 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIDLErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src string
+		err string
+	}{
+		{"struct s { int x[4]; };", "fixed-length arrays of int are not supported, only of opaque"},
+		{"typedef string name<MAX>;", "undefined constant MAX"},
+		{"const A = 1; typedef string name<MAX>;", "undefined constant MAX"},
+		{"const A = B; const B = A; typedef string name<A>;", "constant A is defined in terms of itself"},
+		{"typedef string name<-1>;", "invalid size -1"},
+	} {
+		_, err := translateIDL([]byte(tc.src))
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected %q, got %v", tc.src, tc.err, err)
+		}
+	}
+
+	out, err := translateIDL([]byte("const MAX = LIMIT; const LIMIT = 8; struct s { string n<MAX>; };"))
+	if err != nil || !strings.Contains(string(out), "max:8") {
+		t.Errorf("Expected constants to be followed, got %s, %v", out, err)
+	}
+}
@@ -10,84 +10,294 @@ import (
 	"fmt"
 	"go/ast"
 	"go/format"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"text/template"
+
+	"dario.cat/xdr"
 )
 
 type fieldInfo struct {
-	Name      string
-	IsBasic   bool   // handled by one the native Read/WriteUint64 etc functions
-	IsSlice   bool   // field is a slice of FieldType
-	FieldType string // original type of field, i.e. "int"
-	Encoder   string // the encoder name, i.e. "Uint64" for Read/WriteUint64
-	Convert   string // what to convert to when encoding, i.e. "uint64"
-	Max       int    // max size for slices and strings
-	Submax    int    // max size for strings inside slices
+	Name       string
+	IsBasic    bool   // handled by one the native Read/WriteUint64 etc functions
+	IsSlice    bool   // field is a slice of FieldType
+	IsMap      bool   // field is a map from strings to FieldType
+	FieldType  string // original type of field, i.e. "int"
+	Encoder    string // the encoder name, i.e. "Uint64" for Read/WriteUint64
+	Convert    string // what to convert to when encoding, i.e. "uint64"
+	Max        int    // max size for slices, maps and their keys, and strings
+	Submax     int    // max size for strings inside slices and maps
+	IsEnum     bool   // FieldType is an enum declared in the same file
+	Optional   bool   // field is a pointer, encoded as XDR optional data
+	FixedLen   int    // length of a fixed-size byte array, i.e. 32 for [32]byte
+	Underlying string // basic type of a named FieldType, i.e. "uint64"
+	StructSize int    // smallest encoded size of a struct FieldType declared in the same file
+	EqualBy    string // how Equal compares values of the field, see equalBy
+	Owner      string // name of the struct declaring the field, for xdr.Hints
+
+	deref bool // refers to the value pointed to by an optional field
+}
+
+// Elem returns the field as seen through the pointer of an optional field.
+func (f fieldInfo) Elem() fieldInfo {
+	f.Optional = false
+	f.deref = true
+	return f
+}
+
+// Ref returns the expression for the field's value.
+func (f fieldInfo) Ref() string {
+	if f.deref {
+		return "*o." + f.Name
+	}
+	return "o." + f.Name
+}
+
+// BasicType returns the basic type the field is encoded as.
+func (f fieldInfo) BasicType() string {
+	if f.Underlying != "" {
+		return f.Underlying
+	}
+	return f.FieldType
+}
+
+// Addr returns the expression for a pointer to the field's value.
+func (f fieldInfo) Addr() string {
+	if f.deref {
+		return "o." + f.Name
+	}
+	return "(&o." + f.Name + ")"
 }
 
 type structInfo struct {
-	Name   string
-	Fields []fieldInfo
+	Name      string
+	Fields    []fieldInfo
+	IsUnion   bool       // the first field discriminates the type of the second
+	Arms      []unionArm // union arms, if IsUnion
+	Versioned bool       // encoded with a size prefix, so fields can be appended
+	HasEqual  bool       // the type declares its own Equal method
+	Panic     bool       // UnmarshalXDR panics instead of returning errors
 }
 
-func (i structInfo) SizeExpr() string {
-	var xdrSizes = map[string]int{
-		"int8":   4,
-		"uint8":  4,
-		"int16":  4,
-		"uint16": 4,
-		"int32":  4,
-		"uint32": 4,
-		"int64":  8,
-		"uint64": 8,
-		"int":    8,
-		"bool":   4,
+type unionArm struct {
+	Case    string // discriminant value, i.e. a constant name
+	Type    string // arm type, stored as a pointer in the union; blank for void
+	EqualBy string // how Equal compares arm values, see equalBy
+}
+
+// Disc returns the discriminant field of a union.
+func (i structInfo) Disc() fieldInfo {
+	return i.Fields[0]
+}
+
+// Value returns the arm field of a union.
+func (i structInfo) Value() fieldInfo {
+	return i.Fields[1]
+}
+
+type enumInfo struct {
+	Name      string
+	Values    []string // names of the constants declared with the enum type
+	HasString bool     // the enum type declares its own String method
+	Panic     bool     // UnmarshalXDR panics instead of returning errors
+}
+
+var xdrSizes = map[string]int{
+	"int8":    4,
+	"uint8":   4,
+	"int16":   4,
+	"uint16":  4,
+	"int32":   4,
+	"uint32":  4,
+	"int64":   8,
+	"uint64":  8,
+	"int":     8,
+	"bool":    4,
+	"float32": 4,
+	"float64": 8,
+}
+
+// MinSize returns the smallest encoded size of a single element of a basic,
+// enum or struct field, or zero if it is not known.
+func (f fieldInfo) MinSize() int {
+	if f.IsEnum {
+		return 4
+	}
+	if f.StructSize > 0 {
+		return f.StructSize
+	}
+	if !f.IsBasic {
+		return 0
 	}
+	if size := xdrSizes[f.BasicType()]; size > 0 {
+		return size
+	}
+	// Strings and byte slices are at least a size prefix.
+	return 4
+}
 
+func (i structInfo) SizeExpr() string {
 	var terms []string
+	if i.Versioned {
+		// The size prefix.
+		terms = append(terms, "4")
+	}
 	nl := ""
 	for _, f := range i.Fields {
-		if size := xdrSizes[f.FieldType]; size > 0 {
-			if f.IsSlice {
-				terms = append(terms, nl+"4+len(o."+f.Name+")*"+strconv.Itoa(size))
-			} else {
-				terms = append(terms, strconv.Itoa(size))
-			}
-		} else {
-			switch f.FieldType {
-			case "string", "[]byte":
-				if f.IsSlice {
-					terms = append(terms, nl+"4+xdr.SizeOfSlice(o."+f.Name+")")
-				} else {
-					terms = append(terms, nl+"4+len(o."+f.Name+")+xdr.Padding(len(o."+f.Name+"))")
-				}
-			default:
-				if f.IsSlice {
-					terms = append(terms, nl+"4+xdr.SizeOfSlice(o."+f.Name+")")
-				} else {
-					terms = append(terms, nl+"o."+f.Name+".XDRSize()")
-				}
-			}
+		switch {
+		case f.Optional:
+			// The presence flag; the value is added by OptionalFields.
+			terms = append(terms, "4")
+		case f.FixedLen > 0, xdrSizes[f.BasicType()] > 0 && !f.IsSlice && !f.IsMap:
+			terms = append(terms, f.SizeTerm())
+		default:
+			terms = append(terms, nl+f.SizeTerm())
 		}
 		nl = "\n"
 	}
 	return strings.Join(terms, "+")
 }
 
+// OptionalFields returns the fields encoded as XDR optional data.
+func (i structInfo) OptionalFields() []fieldInfo {
+	var fs []fieldInfo
+	for _, f := range i.Fields {
+		if f.Optional {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// HasSlices reports whether the struct has slice or byte slice fields.
+func (i structInfo) HasSlices() bool {
+	for _, f := range i.Fields {
+		if f.IsSlice || f.BasicType() == "[]byte" {
+			return true
+		}
+	}
+	return false
+}
+
+// FloatDoc returns the sentence documenting how Equal compares the float
+// fields of the struct, if it has any.
+func (i structInfo) FloatDoc() string {
+	for _, f := range i.Fields {
+		switch {
+		case f.EqualBy == "bits":
+			return "Floats are compared by their bits, so NaN equals itself\n// while 0 and -0 differ."
+		case f.IsBasic && strings.HasPrefix(f.BasicType(), "float"):
+			return "Floats are compared with ==, so NaN never equals itself."
+		}
+	}
+	return ""
+}
+
+// EqualCheck returns the statement making Equal return false when the field
+// differs between o and p.
+func (f fieldInfo) EqualCheck() string {
+	a, b := "o."+f.Name, "p."+f.Name
+	switch {
+	case f.Optional:
+		ra := "*" + a
+		if f.EqualBy == "Equal" {
+			ra = a
+		}
+		return "if (" + a + " == nil) != (" + b + " == nil) || " + a + " != nil && " + f.notEqual(ra, "*"+b) + " {\nreturn false\n}"
+	case f.IsSlice:
+		return "if len(" + a + ") != len(" + b + ") {\nreturn false\n}\n" +
+			"for i := range " + a + " {\nif " + f.notEqual(a+"[i]", b+"[i]") + " {\nreturn false\n}\n}"
+	case f.IsMap:
+		return "if len(" + a + ") != len(" + b + ") {\nreturn false\n}\n" +
+			"for k, v := range " + a + " {\nif w, ok := " + b + "[k]; !ok || " + f.notEqual("v", "w") + " {\nreturn false\n}\n}"
+	}
+	return "if " + f.notEqual(a, b) + " {\nreturn false\n}"
+}
+
+// notEqual returns the expression for the values a and b of the field
+// differing.
+func (f fieldInfo) notEqual(a, b string) string {
+	switch f.EqualBy {
+	case "bytes":
+		return "string(" + a + ") != string(" + b + ")"
+	case "bits":
+		bits := "math.Float64bits"
+		if f.BasicType() == "float32" {
+			bits = "math.Float32bits"
+		}
+		if f.Underlying != "" {
+			a, b = f.Underlying+"("+a+")", f.Underlying+"("+b+")"
+		}
+		return bits + "(" + a + ") != " + bits + "(" + b + ")"
+	case "Equal":
+		return "!" + a + ".Equal(" + b + ")"
+	case "reflect":
+		return "!reflect.DeepEqual(" + a + ", " + b + ")"
+	}
+	return a + " != " + b
+}
+
+// Same returns the expression for the arm values a and b, pointers which
+// may be nil, being equal.
+func (a unionArm) Same() string {
+	switch a.EqualBy {
+	case "Equal":
+		return "a == b || a != nil && b != nil && a.Equal(*b)"
+	case "==":
+		return "a == b || a != nil && b != nil && *a == *b"
+	}
+	return "reflect.DeepEqual(a, b)"
+}
+
+// SizeTerm returns the expression for the field's encoded size.
+func (f fieldInfo) SizeTerm() string {
+	if f.IsMap {
+		return "4+xdr.SizeOfMap(o." + f.Name + ")"
+	}
+	if f.FixedLen > 0 {
+		return strconv.Itoa(f.FixedLen + xdr.Padding(f.FixedLen))
+	}
+	if size := xdrSizes[f.BasicType()]; size > 0 {
+		if f.IsSlice {
+			return "4+len(o." + f.Name + ")*" + strconv.Itoa(size)
+		}
+		return strconv.Itoa(size)
+	}
+	if f.IsSlice {
+		return "4+xdr.SizeOfSlice(o." + f.Name + ")"
+	}
+	switch f.BasicType() {
+	case "string":
+		if f.Underlying != "" {
+			return "xdr.StringSize(string(" + f.Ref() + "))"
+		}
+		return "xdr.StringSize(" + f.Ref() + ")"
+	case "[]byte":
+		return "xdr.BytesSize(len(" + f.Ref() + "))"
+	default:
+		return "o." + f.Name + ".XDRSize()"
+	}
+}
+
 var headerData = `// ************************************************************
 // This file is automatically generated by genxdr. Do not edit.
 // ************************************************************
 
 package {{.Package}}
 
-import (
+import ({{range .Imports}}
+	"{{.}}"{{end}}
+
 	"dario.cat/xdr"
 )
 `
@@ -95,14 +305,23 @@ import (
 var encoderData = `
 // XDRSize returns the XDR encoded form's size.
 func (o {{.Name}}) XDRSize() int {
-	return {{.SizeExpr}}
+	{{if .OptionalFields}}
+		s := {{.SizeExpr}}
+		{{range .OptionalFields}}
+			if o.{{.Name}} != nil {
+				s += {{.Elem.SizeTerm}}
+			}
+		{{end}}
+		return s
+	{{else}}
+		return {{.SizeExpr}}
+	{{end}}
 }//+n
 
 // MarshalXDR returns the XDR encoding.
 func (o {{.Name}}) MarshalXDR() ([]byte, error) {
-	buf:= make([]byte, o.XDRSize())
-	m := &xdr.Marshaller{Data: buf}
-	return buf, o.MarshalXDRInto(m)
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
 }//+n
 
 // MustMarshalXDR returns the XDR encoding. MustMarshalXDR
@@ -117,9 +336,19 @@ func (o {{.Name}}) MustMarshalXDR() []byte {
 
 // MarshalXDRInto marshals the struct using the provided Marshaller.
 func (o {{.Name}}) MarshalXDRInto(m *xdr.Marshaller) error {
+	{{if .Versioned}}
+		m.MarshalUint32(uint32(o.XDRSize() - 4))
+	{{end}}
 	{{range $fi := .Fields}}
-		{{if $fi.IsSlice}}
+		{{if $fi.Optional}}
+			m.MarshalBool(o.{{$fi.Name}} != nil)
+			if o.{{$fi.Name}} != nil {
+				{{template "marshalValue" $fi.Elem}}
+			}
+		{{else if $fi.IsSlice}}
 			{{template "marshalSlice" $fi}}
+		{{else if $fi.IsMap}}
+			{{template "marshalMap" $fi}}
 		{{else}}
 			{{template "marshalValue" $fi}}
 		{{end}}
@@ -127,16 +356,45 @@ func (o {{.Name}}) MarshalXDRInto(m *xdr.Marshaller) error {
 	return m.Error
 }//+n
 
+// EncodeXDR writes the struct to the provided Encoder.
+func (o {{.Name}}) EncodeXDR(e *xdr.Encoder) error {
+	{{if .Versioned}}
+		e.EncodeUint32(uint32(o.XDRSize() - 4))
+	{{end}}
+	{{range $fi := .Fields}}
+		{{if $fi.Optional}}
+			e.EncodeBool(o.{{$fi.Name}} != nil)
+			if o.{{$fi.Name}} != nil {
+				{{template "encodeValue" $fi.Elem}}
+			}
+		{{else if $fi.IsSlice}}
+			{{template "encodeSlice" $fi}}
+		{{else if $fi.IsMap}}
+			{{template "encodeMap" $fi}}
+		{{else}}
+			{{template "encodeValue" $fi}}
+		{{end}}
+	{{end}}
+	return e.Err()
+}//+n
+
 {{define "marshalValue"}}
-	{{if ne .Convert ""}}
-		m.Marshal{{.Encoder}}({{.Convert}}(o.{{.Name}}))
+	{{if ge .FixedLen 1}}
+		m.MarshalFixedOpaque(o.{{.Name}}[:])
+	{{else if ne .Convert ""}}
+		{{if ge .Max 1}}
+			if l := len({{.Ref}}); l > {{.Max}} {
+				return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
+			}
+		{{end}}
+		m.Marshal{{.Encoder}}({{.Convert}}({{.Ref}}))
 	{{else if .IsBasic}}
 		{{if ge .Max 1}}
-			if l := len(o.{{.Name}}); l > {{.Max}} {
+			if l := len({{.Ref}}); l > {{.Max}} {
 				return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
 			}
 		{{end}}
-		m.Marshal{{.Encoder}}(o.{{.Name}})
+		m.Marshal{{.Encoder}}({{.Ref}})
 	{{else}}
 		if err := o.{{.Name}}.MarshalXDRInto(m); err != nil {
 			return err
@@ -165,18 +423,175 @@ func (o {{.Name}}) MarshalXDRInto(m *xdr.Marshaller) error {
 	}
 {{end}}
 
+{{define "marshalMap"}}
+	{{if ge .Max 1}}
+		if l := len(o.{{.Name}}); l > {{.Max}} {
+			return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
+		}
+	{{end}}
+
+	m.MarshalUint32(uint32(len(o.{{.Name}})))
+	for _, k := range xdr.SortedKeys(o.{{.Name}}) {
+		{{if ge .Max 1}}
+			if l := len(k); l > {{.Max}} {
+				return xdr.ElementSizeExceeded("{{.Name}} key", l, {{.Max}})
+			}
+		{{end}}
+		m.MarshalString(k)
+		{{if ne .Convert ""}}
+			m.Marshal{{.Encoder}}({{.Convert}}(o.{{.Name}}[k]))
+		{{else if .IsBasic}}
+			m.Marshal{{.Encoder}}(o.{{.Name}}[k])
+		{{else}}
+			if err := o.{{.Name}}[k].MarshalXDRInto(m); err != nil {
+				return err
+			}
+		{{end}}
+	}
+{{end}}
+
+{{define "encodeValue"}}
+	{{if ge .FixedLen 1}}
+		e.EncodeFixedOpaque(o.{{.Name}}[:])
+	{{else if ne .Convert ""}}
+		{{if ge .Max 1}}
+			if l := len({{.Ref}}); l > {{.Max}} {
+				return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
+			}
+		{{end}}
+		e.Encode{{.Encoder}}({{.Convert}}({{.Ref}}))
+	{{else if .IsBasic}}
+		{{if ge .Max 1}}
+			if l := len({{.Ref}}); l > {{.Max}} {
+				return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
+			}
+		{{end}}
+		e.Encode{{.Encoder}}({{.Ref}})
+	{{else}}
+		if err := o.{{.Name}}.EncodeXDR(e); err != nil {
+			return err
+		}
+	{{end}}
+{{end}}
+
+{{define "encodeSlice"}}
+	{{if ge .Max 1}}
+		if l := len(o.{{.Name}}); l > {{.Max}} {
+			return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
+		}
+	{{end}}
+
+	e.EncodeUint32(uint32(len(o.{{.Name}})))
+	for i := range o.{{.Name}} {
+		{{if ne .Convert ""}}
+			e.Encode{{.Encoder}}({{.Convert}}(o.{{.Name}}[i]))
+		{{else if .IsBasic}}
+			e.Encode{{.Encoder}}(o.{{.Name}}[i])
+		{{else}}
+			if err := o.{{.Name}}[i].EncodeXDR(e); err != nil {
+				return err
+			}
+		{{end}}
+	}
+{{end}}
+
+{{define "encodeMap"}}
+	{{if ge .Max 1}}
+		if l := len(o.{{.Name}}); l > {{.Max}} {
+			return xdr.ElementSizeExceeded("{{.Name}}", l, {{.Max}})
+		}
+	{{end}}
+
+	e.EncodeUint32(uint32(len(o.{{.Name}})))
+	for _, k := range xdr.SortedKeys(o.{{.Name}}) {
+		{{if ge .Max 1}}
+			if l := len(k); l > {{.Max}} {
+				return xdr.ElementSizeExceeded("{{.Name}} key", l, {{.Max}})
+			}
+		{{end}}
+		e.EncodeString(k)
+		{{if ne .Convert ""}}
+			e.Encode{{.Encoder}}({{.Convert}}(o.{{.Name}}[k]))
+		{{else if .IsBasic}}
+			e.Encode{{.Encoder}}(o.{{.Name}}[k])
+		{{else}}
+			if err := o.{{.Name}}[k].EncodeXDR(e); err != nil {
+				return err
+			}
+		{{end}}
+	}
+{{end}}
+
 // UnmarshalXDR parses the XDR-encoded data and stores the result in the
-// struct.
+// struct.{{if .Panic}} UnmarshalXDR panics in case of error.{{end}}
 func (o *{{.Name}}) UnmarshalXDR(bs []byte) error {
-	u := &xdr.Unmarshaller{Data: bs}
+	{{if .Panic}}
+		if err := o.UnmarshalXDRInto(bs, nil); err != nil {
+			panic(err)
+		}
+		return nil
+	{{else}}
+		return o.UnmarshalXDRInto(bs, nil)
+	{{end}}
+}//+n
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *{{.Name}}) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
 	return o.UnmarshalXDRFrom(u)
 }
 
+{{if .Versioned}}
 // UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// The struct is versioned: data following the known fields, appended by a
+// newer version, is skipped, and fields missing from the end of the data,
+// written by an older version, are left zero.
 func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := o.unmarshalXDRFields(u.UnmarshalNested()); err != nil {
+		u.Fail(err)
+		return err
+	}
+	return nil
+}//+n
+
+// unmarshalXDRFields unmarshals the fields of the struct from an
+// Unmarshaller over its encoding, stopping at the end of the data.
+func (o *{{.Name}}) unmarshalXDRFields(u *xdr.Unmarshaller) error {
+	*o = {{.Name}}{}
+{{else}}
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+{{end}}
 	{{range $fi := .Fields}}
-		{{if $fi.IsSlice}}
+		{{if $.Versioned}}
+			if u.Remaining() == 0 {
+				return u.Error
+			}
+		{{end}}
+		{{if $fi.Optional}}
+			if u.UnmarshalBool() {
+				if o.{{$fi.Name}} == nil {
+					o.{{$fi.Name}} = new({{$fi.FieldType}})
+				}
+				{{template "unmarshalValue" $fi.Elem}}
+			} else {
+				o.{{$fi.Name}} = nil
+			}
+		{{else if $fi.IsSlice}}
 			{{template "unmarshalSlice" $fi}}
+		{{else if $fi.IsMap}}
+			{{template "unmarshalMap" $fi}}
 		{{else}}
 			{{template "unmarshalValue" $fi}}
 		{{end}}
@@ -185,32 +600,55 @@ func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 }//+n
 
 {{define "unmarshalValue"}}
-	{{if ne .Convert ""}}
-		o.{{.Name}} = {{.FieldType}}(u.Unmarshal{{.Encoder}}())
+	{{if ge .FixedLen 1}}
+		copy(o.{{.Name}}[:], u.UnmarshalFixedOpaque({{.FixedLen}}))
+	{{else if ne .Convert ""}}
+		{{if ge .Max 1}}
+			{{template "checkMax" .}}
+			{{.Ref}} = {{.FieldType}}(u.Unmarshal{{.Encoder}}Max({{.Max}}))
+		{{else}}
+			{{.Ref}} = {{.FieldType}}(u.Unmarshal{{.Encoder}}())
+		{{end}}
 	{{else if .IsBasic}}
 		{{if ge .Max 1}}
-			o.{{.Name}} = u.Unmarshal{{.Encoder}}Max({{.Max}})
+			{{template "checkMax" .}}
+			{{.Ref}} = u.Unmarshal{{.Encoder}}Max({{.Max}})
 		{{else}}
-			o.{{.Name}} = u.Unmarshal{{.Encoder}}()
+			{{.Ref}} = u.Unmarshal{{.Encoder}}()
 		{{end}}
 	{{else}}
-		(&o.{{.Name}}).UnmarshalXDRFrom(u)
+		if err := {{.Addr}}.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
 	{{end}}
 {{end}}
 
+{{define "checkMax"}}
+	if _, err := xdr.CheckLength("{{.Name}}", u.PeekUint32(), {{.Max}}); err != nil {
+		return u.Fail(err)
+	}
+{{end}}
+
+{{define "checkSubmax"}}
+	if _, err := xdr.CheckLength("{{.Name}}", u.PeekUint32(), {{.Submax}}); err != nil {
+		return u.Fail(err)
+	}
+{{end}}
+
 {{define "unmarshalSlice"}}
-	_{{.Name}}Size := int(u.UnmarshalUint32())
-	if _{{.Name}}Size < 0 {
-		return xdr.ElementSizeExceeded("{{.Name}}", _{{.Name}}Size, {{.Max}})
-	} else if _{{.Name}}Size == 0 {
+	_{{.Name}}Size := u.UnmarshalLength("{{.Name}}", {{.Max}})
+	if u.Error != nil {
+		return u.Error
+	}
+	if _{{.Name}}Size == 0 {
 		o.{{.Name}} = nil
 	} else {
-		{{if ge .Max 1}}
-			if _{{.Name}}Size > {{.Max}} {
-				return xdr.ElementSizeExceeded("{{.Name}}", _{{.Name}}Size, {{.Max}})
+		{{if ge .MinSize 1}}
+			if !u.Require(_{{.Name}}Size, {{.MinSize}}) {
+				return u.Error
 			}
 		{{end}}
-		if _{{.Name}}Size <= len(o.{{.Name}}) {
+		if _{{.Name}}Size <= cap(o.{{.Name}}) {
 			{{if eq .FieldType "string"}}
 				for i := _{{.Name}}Size; i < len(o.{{.Name}}); i++ { o.{{.Name}}[i] = "" }
 			{{end}}
@@ -219,19 +657,87 @@ func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 			{{end}}
 			o.{{.Name}} = o.{{.Name}}[:_{{.Name}}Size]
 		} else {
-			o.{{.Name}} = make([]{{.FieldType}}, _{{.Name}}Size)
+			_{{.Name}}Cap := u.Capacity("{{.Owner}}.{{.Name}}", _{{.Name}}Size)
+			if !u.Alloc(_{{.Name}}Cap * int(unsafe.Sizeof(o.{{.Name}}[0]))) {
+				return u.Error
+			}
+			o.{{.Name}} = make([]{{.FieldType}}, _{{.Name}}Size, _{{.Name}}Cap)
 		}
 		for i := range o.{{.Name}} {
 			{{if ne .Convert ""}}
-				o.{{.Name}}[i] = {{.FieldType}}(u.Unmarshal{{.Encoder}}())
+				{{if ge .Submax 1}}
+					{{template "checkSubmax" .}}
+					o.{{.Name}}[i] = {{.FieldType}}(u.Unmarshal{{.Encoder}}Max({{.Submax}}))
+				{{else}}
+					o.{{.Name}}[i] = {{.FieldType}}(u.Unmarshal{{.Encoder}}())
+				{{end}}
 			{{else if .IsBasic}}
 				{{if ge .Submax 1}}
+					{{template "checkSubmax" .}}
 					o.{{.Name}}[i] = u.Unmarshal{{.Encoder}}Max({{.Submax}})
 				{{else}}
 					o.{{.Name}}[i] = u.Unmarshal{{.Encoder}}()
 				{{end}}
 			{{else}}
-				(&o.{{.Name}}[i]).UnmarshalXDRFrom(u)
+				if err := (&o.{{.Name}}[i]).UnmarshalXDRFrom(u); err != nil {
+					return err
+				}
+			{{end}}
+		}
+	}
+{{end}}
+
+{{define "unmarshalMap"}}
+	_{{.Name}}Size := u.UnmarshalLength("{{.Name}}", {{.Max}})
+	if u.Error != nil {
+		return u.Error
+	}
+	if _{{.Name}}Size == 0 {
+		o.{{.Name}} = nil
+	} else {
+		if !u.Require(_{{.Name}}Size, 4+{{.MinSize}}) {
+			return u.Error
+		}
+		_{{.Name}}Cap := u.Capacity("{{.Owner}}.{{.Name}}", _{{.Name}}Size)
+		if !u.Alloc(_{{.Name}}Cap * int(unsafe.Sizeof("")+unsafe.Sizeof(o.{{.Name}}[""]))) {
+			return u.Error
+		}
+		o.{{.Name}} = make(map[string]{{.FieldType}}, _{{.Name}}Cap)
+		var _{{.Name}}Prev string
+		for i := 0; i < _{{.Name}}Size; i++ {
+			{{if ge .Max 1}}
+				if _, err := xdr.CheckLength("{{.Name}} key", u.PeekUint32(), {{.Max}}); err != nil {
+					return u.Fail(err)
+				}
+				k := u.UnmarshalStringMax({{.Max}})
+			{{else}}
+				k := u.UnmarshalString()
+			{{end}}
+			if u.Strict && i > 0 && k <= _{{.Name}}Prev {
+				// Keys are marshalled sorted, so duplicates are rejected too.
+				return u.Fail(xdr.ErrMapKeyOrder)
+			}
+			_{{.Name}}Prev = k
+			{{if ne .Convert ""}}
+				{{if ge .Submax 1}}
+					{{template "checkSubmax" .}}
+					o.{{.Name}}[k] = {{.FieldType}}(u.Unmarshal{{.Encoder}}Max({{.Submax}}))
+				{{else}}
+					o.{{.Name}}[k] = {{.FieldType}}(u.Unmarshal{{.Encoder}}())
+				{{end}}
+			{{else if .IsBasic}}
+				{{if ge .Submax 1}}
+					{{template "checkSubmax" .}}
+					o.{{.Name}}[k] = u.Unmarshal{{.Encoder}}Max({{.Submax}})
+				{{else}}
+					o.{{.Name}}[k] = u.Unmarshal{{.Encoder}}()
+				{{end}}
+			{{else}}
+				var v {{.FieldType}}
+				if err := v.UnmarshalXDRFrom(u); err != nil {
+					return err
+				}
+				o.{{.Name}}[k] = v
 			{{end}}
 		}
 	}
@@ -243,6 +749,73 @@ var (
 	headerTpl = template.Must(template.New("header").Parse(headerData))
 )
 
+// commonTpl holds the methods generated the same way for every type.
+var commonTpl = template.Must(template.New("common").Parse(`
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o {{.Name}}) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}//+n
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o {{.Name}}) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}//+n
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.{{if .Panic}} Unlike UnmarshalXDR, it returns
+// errors rather than panicking, as that interface requires.{{end}}
+func (o *{{.Name}}) UnmarshalBinary(bs []byte) error {
+	{{if .Panic}}
+		return o.UnmarshalXDRFrom(&xdr.Unmarshaller{Data: bs})
+	{{else}}
+		return o.UnmarshalXDR(bs)
+	{{end}}
+}//+n
+`))
+
+// equalTpl holds the Equal method of structs and unions.
+var equalTpl = template.Must(template.New("equal").Parse(`
+{{if .IsUnion}}
+// Equal reports whether o and p have the same discriminant and equal arm
+// values.
+func (o {{.Name}}) Equal(p {{.Name}}) bool {
+	{{.Disc.EqualCheck}}
+	switch o.{{.Disc.Name}} {
+	{{range .Arms}}{{if .Type}}
+	case {{.Case}}:
+		a, _ := o.{{$.Value.Name}}.(*{{.Type}})
+		b, _ := p.{{$.Value.Name}}.(*{{.Type}})
+		return {{.Same}}
+	{{end}}{{end}}
+	}
+	return true
+}//+n
+{{else if not .Fields}}
+// Equal reports whether o and p are equal, which they always are as the
+// struct has no encoded fields.
+func (o {{.Name}}) Equal(p {{.Name}}) bool {
+	return true
+}//+n
+{{else}}
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.{{if .HasSlices}} Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.{{end}}{{with .FloatDoc}}
+// {{.}}{{end}}
+func (o {{.Name}}) Equal(p {{.Name}}) bool {
+	{{range .Fields}}
+		{{.EqualCheck}}
+	{{end}}
+	return true
+}//+n
+{{end}}
+`))
+
 var emptyTypeTpl = template.Must(template.New("encoder").Parse(`
 // XDRSize returns the XDR encoded form's size.
 func (o {{.Name}}) XDRSize() int {
@@ -265,6 +838,11 @@ func (o {{.Name}}) MarshalXDRInto(m *xdr.Marshaller) error {
 	return nil
 }//+n
 
+// EncodeXDR writes the struct to the provided Encoder.
+func (o {{.Name}}) EncodeXDR(e *xdr.Encoder) error {
+	return nil
+}//+n
+
 // UnmarshalXDR parses the XDR-encoded data and stores the result in the
 // struct.
 func (o *{{.Name}}) UnmarshalXDR(bs []byte) error {
@@ -277,7 +855,220 @@ func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 }//+n
 `))
 
-var maxRe = regexp.MustCompile(`(?:\Wmax:)(\d+)(?:\s*,\s*(\d+))?`)
+var enumTpl = template.Must(template.New("enum").Parse(`
+// XDRSize returns the XDR encoded form's size.
+func (o {{.Name}}) XDRSize() int {
+	return 4
+}//+n
+
+// MarshalXDR returns the XDR encoding.
+func (o {{.Name}}) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}//+n
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o {{.Name}}) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}//+n
+
+// MarshalXDRInto marshals the enum using the provided Marshaller.
+func (o {{.Name}}) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalInt32(int32(o))
+	return m.Error
+}//+n
+
+// EncodeXDR writes the enum to the provided Encoder.
+func (o {{.Name}}) EncodeXDR(e *xdr.Encoder) error {
+	return e.EncodeInt32(int32(o))
+}//+n
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// enum.{{if .Panic}} UnmarshalXDR panics in case of error.{{end}}
+func (o *{{.Name}}) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	{{if .Panic}}
+		if err := o.UnmarshalXDRFrom(u); err != nil {
+			panic(err)
+		}
+		return nil
+	{{else}}
+		return o.UnmarshalXDRFrom(u)
+	{{end}}
+}//+n
+
+// UnmarshalXDRFrom unmarshals the enum using the provided Unmarshaller.
+// Values other than the declared {{.Name}} constants are rejected.
+func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	v := {{.Name}}(u.UnmarshalInt32())
+	if u.Error != nil {
+		return u.Error
+	}
+	switch v {
+	{{if .Values}}
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v}}{{end}}:
+	{{end}}
+	default:
+		return u.Fail(xdr.InvalidEnumValue("{{.Name}}", int32(v)))
+	}
+	*o = v
+	return nil
+}//+n
+{{if not .HasString}}
+// String returns the name of the {{.Name}} constant equal to o, or the
+// numeric value for unknown values.
+func (o {{.Name}}) String() string {
+	switch o {
+	{{range .Values}}
+	case {{.}}:
+		return "{{.}}"
+	{{end}}
+	}
+	return "{{.Name}}(" + strconv.FormatInt(int64(o), 10) + ")"
+}//+n
+{{end}}
+`))
+
+var unionData = `
+// XDRSize returns the XDR encoded form's size.
+func (o {{.Name}}) XDRSize() int {
+	switch o.{{.Disc.Name}} {
+	{{range .Arms}}{{if .Type}}
+	case {{.Case}}:
+		if v, ok := o.{{$.Value.Name}}.(*{{.Type}}); ok {
+			return 4 + v.XDRSize()
+		}
+	{{end}}{{end}}
+	}
+	return 4
+}//+n
+
+// MarshalXDR returns the XDR encoding.
+func (o {{.Name}}) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}//+n
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o {{.Name}}) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}//+n
+
+// MarshalXDRInto marshals the union using the provided Marshaller.
+func (o {{.Name}}) MarshalXDRInto(m *xdr.Marshaller) error {
+	switch o.{{.Disc.Name}} {
+	{{range .Arms}}
+	case {{.Case}}:
+		{{if .Type}}
+			v, ok := o.{{$.Value.Name}}.(*{{.Type}})
+			if !ok {
+				return xdr.InvalidUnionArm("{{$.Name}}", o.{{$.Disc.Name}})
+			}
+			{{template "marshalValue" $.Disc}}
+			if err := v.MarshalXDRInto(m); err != nil {
+				return err
+			}
+		{{else}}
+			{{template "marshalValue" $.Disc}}
+		{{end}}
+	{{end}}
+	default:
+		return xdr.InvalidUnionArm("{{.Name}}", o.{{.Disc.Name}})
+	}
+	return m.Error
+}//+n
+
+// EncodeXDR writes the union to the provided Encoder.
+func (o {{.Name}}) EncodeXDR(e *xdr.Encoder) error {
+	switch o.{{.Disc.Name}} {
+	{{range .Arms}}
+	case {{.Case}}:
+		{{if .Type}}
+			v, ok := o.{{$.Value.Name}}.(*{{.Type}})
+			if !ok {
+				return xdr.InvalidUnionArm("{{$.Name}}", o.{{$.Disc.Name}})
+			}
+			{{template "encodeValue" $.Disc}}
+			if err := v.EncodeXDR(e); err != nil {
+				return err
+			}
+		{{else}}
+			{{template "encodeValue" $.Disc}}
+		{{end}}
+	{{end}}
+	default:
+		return xdr.InvalidUnionArm("{{.Name}}", o.{{.Disc.Name}})
+	}
+	return e.Err()
+}//+n
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// union.{{if .Panic}} UnmarshalXDR panics in case of error.{{end}}
+func (o *{{.Name}}) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	{{if .Panic}}
+		if err := o.UnmarshalXDRFrom(u); err != nil {
+			panic(err)
+		}
+		return nil
+	{{else}}
+		return o.UnmarshalXDRFrom(u)
+	{{end}}
+}//+n
+
+// UnmarshalXDRFrom unmarshals the union using the provided Unmarshaller.
+func (o *{{.Name}}) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	{{template "unmarshalValue" .Disc}}
+	if u.Error != nil {
+		return u.Error
+	}
+	switch o.{{.Disc.Name}} {
+	{{range .Arms}}
+	case {{.Case}}:
+		{{if .Type}}
+			v, ok := o.{{$.Value.Name}}.(*{{.Type}})
+			if !ok {
+				v = new({{.Type}})
+			}
+			if err := v.UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
+			o.{{$.Value.Name}} = v
+		{{else}}
+			o.{{$.Value.Name}} = nil
+		{{end}}
+	{{end}}
+	default:
+		u.Fail(xdr.InvalidUnionArm("{{.Name}}", o.{{.Disc.Name}}))
+	}
+	return u.Error
+}//+n
+`
+
+var unionTpl = template.Must(template.Must(encodeTpl.Clone()).New("union").Parse(unionData))
+
+// A union arm list is given in the comment of the second field of a union,
+// i.e. "arms: StatusOK=Success, StatusFailed=Failure, StatusUnknown=void".
+var armsRe = regexp.MustCompile(`arms:\s*(.*)`)
+
+// The max sizes of a field are given in its comment, either as "max:N" or
+// as "xdr:max=N", optionally followed by the max size of the strings or byte
+// slices inside a slice, i.e. "max:16, 64".
+var maxRe = regexp.MustCompile(`(?:\Wmax[:=])(\d+)(?:\s*,\s*(\d+))?`)
 
 type typeSet struct {
 	Type    string
@@ -285,21 +1076,26 @@ type typeSet struct {
 }
 
 var xdrEncoders = map[string]typeSet{
-	"int8":   typeSet{"uint8", "Uint8"},
-	"uint8":  typeSet{"", "Uint8"},
-	"int16":  typeSet{"uint16", "Uint16"},
-	"uint16": typeSet{"", "Uint16"},
-	"int32":  typeSet{"uint32", "Uint32"},
-	"uint32": typeSet{"", "Uint32"},
-	"int64":  typeSet{"uint64", "Uint64"},
-	"uint64": typeSet{"", "Uint64"},
-	"int":    typeSet{"uint64", "Uint64"},
-	"string": typeSet{"", "String"},
-	"[]byte": typeSet{"", "Bytes"},
-	"bool":   typeSet{"", "Bool"},
-}
-
-func handleStruct(t *ast.StructType) []fieldInfo {
+	"int8":    typeSet{"uint8", "Uint8"},
+	"uint8":   typeSet{"", "Uint8"},
+	"int16":   typeSet{"uint16", "Uint16"},
+	"uint16":  typeSet{"", "Uint16"},
+	"int32":   typeSet{"uint32", "Uint32"},
+	"uint32":  typeSet{"", "Uint32"},
+	"int64":   typeSet{"uint64", "Uint64"},
+	"uint64":  typeSet{"", "Uint64"},
+	"int":     typeSet{"uint64", "Uint64"},
+	"string":  typeSet{"", "String"},
+	"[]byte":  typeSet{"", "Bytes"},
+	"bool":    typeSet{"", "Bool"},
+	"float32": typeSet{"", "Float32"},
+	"float64": typeSet{"", "Float64"},
+}
+
+// handleStruct returns the fields of the struct named name to encode. Fields
+// of types genxdr cannot encode are fatal, unless tagged `xdr:"-"`, so that
+// they do not silently vanish from the encoding.
+func handleStruct(name string, t *ast.StructType) []fieldInfo {
 	var fs []fieldInfo
 
 	for _, sf := range t.Fields.List {
@@ -323,9 +1119,33 @@ func handleStruct(t *ast.StructType) []fieldInfo {
 				continue
 			}
 		}
+		if sf.Tag != nil {
+			tag, _ := strconv.Unquote(sf.Tag.Value)
+			ft, err := xdr.ParseFieldTag(reflect.StructTag(tag).Get("xdr"))
+			if err != nil {
+				log.Fatalf("field %s: %v", fn, err)
+			}
+			if ft.Skip {
+				continue
+			}
+			if ft.Max > 0 {
+				max1 = ft.Max
+			}
+		}
+
+		unsupported := func() {
+			log.Fatalf("struct %s field %s: unsupported type %s", name, fn, types.ExprString(sf.Type))
+		}
+
+		typ := sf.Type
+		optional := false
+		if st, ok := typ.(*ast.StarExpr); ok {
+			typ = st.X
+			optional = true
+		}
 
 		var f fieldInfo
-		switch ft := sf.Type.(type) {
+		switch ft := typ.(type) {
 		case *ast.Ident:
 			tn := ft.Name
 			if enc, ok := xdrEncoders[tn]; ok {
@@ -350,26 +1170,86 @@ func handleStruct(t *ast.StructType) []fieldInfo {
 
 		case *ast.ArrayType:
 			if ft.Len != nil {
-				// We don't handle arrays
-				continue
+				n := fixedByteArrayLen(ft)
+				if n <= 0 {
+					// We only handle arrays of bytes
+					unsupported()
+				}
+				f = fieldInfo{
+					Name:      fn,
+					FieldType: "[" + strconv.Itoa(n) + "]byte",
+					FixedLen:  n,
+				}
+				break
 			}
 
-			tn := ft.Elt.(*ast.Ident).Name
+			var tn string
+			switch et := ft.Elt.(type) {
+			case *ast.Ident:
+				tn = et.Name
+			case *ast.SelectorExpr:
+				tn = et.X.(*ast.Ident).Name + "." + et.Sel.Name
+			default:
+				// We don't handle slices of other types
+				unsupported()
+			}
 			if enc, ok := xdrEncoders["[]"+tn]; ok {
 				f = fieldInfo{
 					Name:      fn,
-					IsBasic:   true,
-					FieldType: "[]" + tn,
-					Encoder:   enc.Encoder,
-					Convert:   enc.Type,
+					IsBasic:   true,
+					FieldType: "[]" + tn,
+					Encoder:   enc.Encoder,
+					Convert:   enc.Type,
+					Max:       max1,
+					Submax:    max2,
+				}
+			} else if enc, ok := xdrEncoders[tn]; ok {
+				f = fieldInfo{
+					Name:      fn,
+					IsBasic:   true,
+					IsSlice:   true,
+					FieldType: tn,
+					Encoder:   enc.Encoder,
+					Convert:   enc.Type,
+					Max:       max1,
+					Submax:    max2,
+				}
+			} else {
+				f = fieldInfo{
+					Name:      fn,
+					IsSlice:   true,
+					FieldType: tn,
 					Max:       max1,
 					Submax:    max2,
 				}
-			} else if enc, ok := xdrEncoders[tn]; ok {
+			}
+
+		case *ast.MapType:
+			if kt, ok := ft.Key.(*ast.Ident); !ok || kt.Name != "string" {
+				// We only handle maps with string keys
+				unsupported()
+			}
+
+			var tn string
+			switch vt := ft.Value.(type) {
+			case *ast.Ident:
+				tn = vt.Name
+			case *ast.SelectorExpr:
+				tn = vt.X.(*ast.Ident).Name + "." + vt.Sel.Name
+			case *ast.ArrayType:
+				if et, ok := vt.Elt.(*ast.Ident); ok && vt.Len == nil && et.Name == "byte" {
+					tn = "[]byte"
+				}
+			}
+			if tn == "" {
+				// We don't handle maps of other types
+				unsupported()
+			}
+			if enc, ok := xdrEncoders[tn]; ok {
 				f = fieldInfo{
 					Name:      fn,
 					IsBasic:   true,
-					IsSlice:   true,
+					IsMap:     true,
 					FieldType: tn,
 					Encoder:   enc.Encoder,
 					Convert:   enc.Type,
@@ -379,7 +1259,7 @@ func handleStruct(t *ast.StructType) []fieldInfo {
 			} else {
 				f = fieldInfo{
 					Name:      fn,
-					IsSlice:   true,
+					IsMap:     true,
 					FieldType: tn,
 					Max:       max1,
 					Submax:    max2,
@@ -387,12 +1267,32 @@ func handleStruct(t *ast.StructType) []fieldInfo {
 			}
 
 		case *ast.SelectorExpr:
+			// Keep the package qualifier, which allocating an optional
+			// field needs.
 			f = fieldInfo{
 				Name:      fn,
-				FieldType: ft.Sel.Name,
+				FieldType: ft.X.(*ast.Ident).Name + "." + ft.Sel.Name,
 				Max:       max1,
 				Submax:    max2,
 			}
+
+		case *ast.InterfaceType:
+			// Only meaningful as the arm of a union
+			f = fieldInfo{
+				Name:      fn,
+				FieldType: "interface{}",
+			}
+
+		default:
+			unsupported()
+		}
+
+		if optional {
+			if f.IsSlice || f.IsMap || f.FixedLen > 0 || f.FieldType == "[]byte" || f.FieldType == "interface{}" {
+				// We only handle pointers to values
+				unsupported()
+			}
+			f.Optional = true
 		}
 
 		fs = append(fs, f)
@@ -401,10 +1301,177 @@ func handleStruct(t *ast.StructType) []fieldInfo {
 	return fs
 }
 
+// fixedByteArrayLen returns the length of a [N]byte or [N]uint8 array type
+// with a literal length, or zero for any other array.
+func fixedByteArrayLen(t *ast.ArrayType) int {
+	et, ok := t.Elt.(*ast.Ident)
+	if !ok || et.Name != "byte" && et.Name != "uint8" {
+		return 0
+	}
+	l, ok := t.Len.(*ast.BasicLit)
+	if !ok || l.Kind != token.INT {
+		return 0
+	}
+	n, _ := strconv.Atoi(l.Value)
+	return n
+}
+
+// equalBy returns how Equal compares values of the field: with "==", as
+// strings for "bytes", by their bits for "bits", with their own Equal method
+// for "Equal", or with reflect.DeepEqual for "reflect". Structs declared in
+// the same file get a generated Equal method.
+func equalBy(f fieldInfo, pkg *types.Package, local map[string]bool, floatBits bool) string {
+	switch {
+	case f.FixedLen > 0, f.IsEnum:
+		return "=="
+	case f.IsBasic:
+		switch f.BasicType() {
+		case "[]byte":
+			return "bytes"
+		case "float32", "float64":
+			if floatBits {
+				return "bits"
+			}
+		}
+		return "=="
+	case local[f.FieldType], hasMethod(pkg, f.FieldType, "Equal"):
+		return "Equal"
+	}
+	if pkg != nil {
+		if tn, ok := pkg.Scope().Lookup(f.FieldType).(*types.TypeName); ok && types.Comparable(tn.Type()) {
+			return "=="
+		}
+	}
+	return "reflect"
+}
+
+// typeCheck type checks the file, so that the underlying types of the named
+// types it declares can be resolved. The generated methods don't exist yet,
+// so errors are expected and ignored.
+func typeCheck(fset *token.FileSet, f *ast.File) *types.Package {
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error:    func(error) {},
+	}
+	pkg, _ := conf.Check(f.Name.Name, fset, []*ast.File{f}, nil)
+	return pkg
+}
+
+// resolveNamed turns a field of a named type declared in the package, with
+// a basic underlying type, into a basic field converted to and from that
+// type. Named types with their own MarshalXDRInto method are left alone.
+func resolveNamed(f *fieldInfo, pkg *types.Package) {
+	if f.IsBasic || pkg == nil {
+		return
+	}
+	tn, ok := pkg.Scope().Lookup(f.FieldType).(*types.TypeName)
+	if !ok {
+		return
+	}
+	if hasMethod(pkg, f.FieldType, "MarshalXDRInto") {
+		return
+	}
+
+	var under string
+	switch ut := tn.Type().Underlying().(type) {
+	case *types.Basic:
+		under = ut.Name()
+		switch ut.Kind() {
+		case types.Byte:
+			under = "uint8"
+		case types.Rune:
+			under = "int32"
+		}
+	case *types.Slice:
+		if b, ok := ut.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			under = "[]byte"
+		}
+	}
+	enc, ok := xdrEncoders[under]
+	if !ok {
+		return
+	}
+
+	f.IsBasic = true
+	f.Underlying = under
+	f.Encoder = enc.Encoder
+	f.Convert = enc.Type
+	if f.Convert == "" {
+		f.Convert = under
+	}
+}
+
+// hasMethod reports whether the named type typ declared in the package has
+// a method with the given name.
+func hasMethod(pkg *types.Package, typ, method string) bool {
+	if pkg == nil {
+		return false
+	}
+	tn, ok := pkg.Scope().Lookup(typ).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	named, ok := tn.Type().(*types.Named)
+	if !ok {
+		return false
+	}
+	for i := 0; i < named.NumMethods(); i++ {
+		if named.Method(i).Name() == method {
+			return true
+		}
+	}
+	return false
+}
+
+func handleUnion(name string, t *ast.StructType) []unionArm {
+	fl := t.Fields.List
+	if len(fl) != 2 || len(fl[0].Names) != 1 || len(fl[1].Names) != 1 {
+		log.Fatalf("union %s must have exactly a discriminant and an arm field", name)
+	}
+
+	var m []string
+	if fl[1].Comment != nil {
+		m = armsRe.FindStringSubmatch(fl[1].Comment.List[0].Text)
+	}
+	if len(m) < 2 {
+		log.Fatalf("union %s is missing its arms list", name)
+	}
+
+	var arms []unionArm
+	for _, a := range strings.Split(m[1], ",") {
+		c, typ, ok := strings.Cut(a, "=")
+		if !ok {
+			log.Fatalf("union %s has a malformed arm %q", name, a)
+		}
+		arm := unionArm{Case: strings.TrimSpace(c), Type: strings.TrimSpace(typ)}
+		if arm.Type == "void" {
+			arm.Type = ""
+		}
+		arms = append(arms, arm)
+	}
+	return arms
+}
+
+func generateEnumCode(output io.Writer, e enumInfo) {
+	var buf bytes.Buffer
+	if err := enumTpl.Execute(&buf, e); err != nil {
+		panic(err)
+	}
+	if err := commonTpl.Execute(&buf, e); err != nil {
+		panic(err)
+	}
+
+	bs := regexp.MustCompile(`(\s*\n)+`).ReplaceAll(buf.Bytes(), []byte("\n"))
+	bs = bytes.Replace(bs, []byte("//+n"), []byte("\n"), -1)
+	output.Write(bs)
+}
+
 func generateCode(output io.Writer, s structInfo) {
 	var buf bytes.Buffer
 	var err error
-	if len(s.Fields) == 0 {
+	if s.IsUnion {
+		err = unionTpl.ExecuteTemplate(&buf, "union", s)
+	} else if len(s.Fields) == 0 {
 		// This is an empty type. We can create a quite simple codec for it.
 		err = emptyTypeTpl.Execute(&buf, s)
 	} else {
@@ -414,9 +1481,212 @@ func generateCode(output io.Writer, s structInfo) {
 	if err != nil {
 		panic(err)
 	}
+	if err := commonTpl.Execute(&buf, s); err != nil {
+		panic(err)
+	}
+	if !s.HasEqual {
+		if err := equalTpl.Execute(&buf, s); err != nil {
+			panic(err)
+		}
+	}
+
+	bs := regexp.MustCompile(`(\s*\n)+`).ReplaceAll(buf.Bytes(), []byte("\n"))
+	bs = bytes.Replace(bs, []byte("//+n"), []byte("\n"), -1)
+	output.Write(bs)
+}
+
+var testTpl = template.Must(template.New("tests").Parse(`// ************************************************************
+// This file is automatically generated by genxdr. Do not edit.
+// ************************************************************
+//+n
+package {{.Package}}
+//+n
+import (
+	"testing"
+	//+n
+	"dario.cat/xdr"
+)
+//+n
+{{range .Samples}}
+func TestXDRRoundTrip{{.Name}}(t *testing.T) {
+	if err := xdr.RoundTrip({{.Expr}}); err != nil {
+		t.Error(err)
+	}
+}//+n
+
+func FuzzUnmarshal{{.Name}}(f *testing.F) {
+	if bs, err := ({{.Expr}}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o {{.Name}}
+		{{if $.Panic}}
+			o.UnmarshalBinary(data)
+		{{else}}
+			o.UnmarshalXDR(data)
+		{{end}}
+	})
+}//+n
+{{end}}
+`))
+
+// sample is a value of a generated type, used as a round trip test case and
+// fuzzing seed.
+type sample struct {
+	Name string // type name
+	Expr string // Go expression for the value
+}
+
+// sampler builds the smallest valid value of each generated type: the zero
+// value, except that enums take their first declared constant and unions
+// their first arm.
+type sampler struct {
+	structs map[string]structInfo
+	enums   map[string]enumInfo
+}
+
+// maxSampleDepth bounds the nesting of sample values, which union arms could
+// otherwise make infinite.
+const maxSampleDepth = 8
+
+// expr returns the sample expression for the named type, or false if no
+// valid value could be built.
+func (s sampler) expr(typ string, depth int) (string, bool) {
+	if depth > maxSampleDepth {
+		return "", false
+	}
+
+	if e, ok := s.enums[typ]; ok {
+		if len(e.Values) == 0 {
+			return "", false
+		}
+		return e.Values[0], true
+	}
+
+	si, ok := s.structs[typ]
+	if !ok {
+		return "", false
+	}
+
+	if si.IsUnion {
+		for _, arm := range si.Arms {
+			if arm.Type == "" {
+				return fmt.Sprintf("%s{%s: %s}", typ, si.Disc().Name, arm.Case), true
+			}
+			if _, ok := s.structs[arm.Type]; !ok {
+				continue
+			}
+			if v, ok := s.expr(arm.Type, depth+1); ok {
+				return fmt.Sprintf("%s{%s: %s, %s: &%s}", typ, si.Disc().Name, arm.Case, si.Value().Name, v), true
+			}
+		}
+		return "", false
+	}
+
+	var fields []string
+	for _, f := range si.Fields {
+		if f.Optional || f.IsSlice || f.IsMap || f.FixedLen > 0 || f.IsBasic {
+			continue
+		}
+		_, isStruct := s.structs[f.FieldType]
+		if !f.IsEnum && !isStruct {
+			continue
+		}
+		v, ok := s.expr(f.FieldType, depth+1)
+		if !ok {
+			return "", false
+		}
+		if v != f.FieldType+"{}" {
+			fields = append(fields, f.Name+": "+v)
+		}
+	}
+	return typ + "{" + strings.Join(fields, ", ") + "}", true
+}
+
+// structMinSizes returns the smallest encoded size of each struct, so that
+// slices of them can be bounds checked before allocating.
+func structMinSizes(structs []structInfo) map[string]int {
+	byName := make(map[string]structInfo)
+	for _, s := range structs {
+		byName[s.Name] = s
+	}
+
+	sizes := make(map[string]int)
+	var size func(name string, depth int) int
+	size = func(name string, depth int) int {
+		if l, ok := sizes[name]; ok {
+			return l
+		}
+		s, ok := byName[name]
+		if !ok || depth > maxSampleDepth {
+			return 0
+		}
+		if s.IsUnion || s.Versioned {
+			// A discriminant, whatever the arm, or a size prefix, whatever
+			// the version.
+			return 4
+		}
+
+		l := 0
+		for _, f := range s.Fields {
+			switch {
+			case f.Optional, f.IsSlice, f.IsMap:
+				l += 4
+			case f.FixedLen > 0:
+				l += f.FixedLen + xdr.Padding(f.FixedLen)
+			case f.IsEnum || f.IsBasic:
+				l += f.MinSize()
+			default:
+				l += size(f.FieldType, depth+1)
+			}
+		}
+		sizes[name] = l
+		return l
+	}
+
+	for _, s := range structs {
+		size(s.Name, 0)
+	}
+	return sizes
+}
+
+// generateTests writes round trip and fuzz tests for the generated types.
+// Types for which no valid value could be built are left out. With panicking
+// UnmarshalXDR methods, fuzzing goes through UnmarshalBinary, which returns
+// errors instead.
+func generateTests(output io.Writer, pkg string, structs []structInfo, enums []enumInfo, panicOnError bool) {
+	s := sampler{structs: make(map[string]structInfo), enums: make(map[string]enumInfo)}
+	for _, si := range structs {
+		s.structs[si.Name] = si
+	}
+	for _, e := range enums {
+		s.enums[e.Name] = e
+	}
+
+	var samples []sample
+	for _, e := range enums {
+		if v, ok := s.expr(e.Name, 0); ok {
+			samples = append(samples, sample{Name: e.Name, Expr: v})
+		}
+	}
+	for _, si := range structs {
+		if v, ok := s.expr(si.Name, 0); ok {
+			samples = append(samples, sample{Name: si.Name, Expr: v})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := testTpl.Execute(&buf, map[string]interface{}{"Package": pkg, "Samples": samples, "Panic": panicOnError}); err != nil {
+		panic(err)
+	}
 
 	bs := regexp.MustCompile(`(\s*\n)+`).ReplaceAll(buf.Bytes(), []byte("\n"))
 	bs = bytes.Replace(bs, []byte("//+n"), []byte("\n"), -1)
+	bs, err := format.Source(bs)
+	if err != nil {
+		log.Print(buf.String())
+		log.Fatal(err)
+	}
 	output.Write(bs)
 }
 
@@ -444,18 +1714,46 @@ func generateDiagram(output io.Writer, s structInfo) {
 	fmt.Fprintln(output, " 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1")
 	line := "+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+"
 	fmt.Fprintln(output, line)
+	if s.Versioned {
+		fmt.Fprintf(output, "| %s |\n", center("Length of "+uncamelize(sn), 61))
+		fmt.Fprintln(output, line)
+	}
 
 	for _, f := range fs {
-		tn := f.FieldType
+		tn := f.BasicType()
+		if f.IsEnum {
+			// Enums are encoded as a signed 32-bit integer
+			tn = "int32"
+		}
 		name := uncamelize(f.Name)
 
 		suffix := ""
+		if f.Optional {
+			fmt.Fprintf(output, "| %s |V|\n", center("Has "+name+" (V=0 or 1)", 59))
+			fmt.Fprintln(output, line)
+		}
 		if f.IsSlice {
 			fmt.Fprintf(output, "| %s |\n", center("Number of "+name, 61))
 			fmt.Fprintln(output, line)
 			suffix = " (n items)"
 			fmt.Fprintf(output, "/ %s /\n", center("", 61))
 		}
+		if f.IsMap {
+			fmt.Fprintf(output, "| %s |\n", center("Number of "+name, 61))
+			fmt.Fprintln(output, line)
+			fmt.Fprintf(output, "/ %s /\n", center("", 61))
+			fmt.Fprintf(output, "\\ %s \\\n", center("Zero or more key/value pairs, sorted by key:", 61))
+			fmt.Fprintf(output, "\\ %s \\\n", center("Key (length + padded data)", 61))
+			if f.IsBasic {
+				tn = tn + " Value"
+			} else {
+				tn = tn + " Structure"
+			}
+			fmt.Fprintf(output, "\\ %s \\\n", center(tn, 61))
+			fmt.Fprintf(output, "/ %s /\n", center("", 61))
+			fmt.Fprintln(output, line)
+			continue
+		}
 		switch tn {
 		case "bool":
 			fmt.Fprintf(output, "| %s |V|\n", center(name+" (V=0 or 1)", 59))
@@ -463,9 +1761,9 @@ func generateDiagram(output io.Writer, s structInfo) {
 			fmt.Fprintf(output, "| %s | %s |\n", center("16 zero bits", 29), center(name, 29))
 		case "int8", "uint8":
 			fmt.Fprintf(output, "| %s | %s |\n", center("24 zero bits", 45), center(name, 13))
-		case "int32", "uint32":
+		case "int32", "uint32", "float32":
 			fmt.Fprintf(output, "| %s |\n", center(name+suffix, 61))
-		case "int64", "uint64":
+		case "int64", "uint64", "float64":
 			fmt.Fprintf(output, "| %-61s |\n", "")
 			fmt.Fprintf(output, "+ %s +\n", center(name+" (64 bits)", 61))
 			fmt.Fprintf(output, "| %-61s |\n", "")
@@ -474,7 +1772,11 @@ func generateDiagram(output io.Writer, s structInfo) {
 			fmt.Fprintf(output, "\\ %s \\\n", center(name+" (length + padded data)", 61))
 			fmt.Fprintf(output, "/ %61s /\n", "")
 		default:
-			if f.IsSlice {
+			if f.FixedLen > 0 {
+				fmt.Fprintf(output, "/ %61s /\n", "")
+				fmt.Fprintf(output, "\\ %s \\\n", center(fmt.Sprintf("%s (%d bytes + padding)", name, f.FixedLen), 61))
+				fmt.Fprintf(output, "/ %61s /\n", "")
+			} else if f.IsSlice {
 				tn = "Zero or more " + tn + " Structures"
 				fmt.Fprintf(output, "\\ %s \\\n", center(tn, 61))
 			} else {
@@ -498,10 +1800,12 @@ func generateXdr(output io.Writer, s structInfo) {
 	sn := s.Name
 	fs := s.Fields
 
+	if s.Versioned {
+		fmt.Fprintln(output, "// Versioned: encoded as variable-length opaque data.")
+	}
 	fmt.Fprintf(output, "struct %s {\n", sn)
 
 	for _, f := range fs {
-		tn := f.FieldType
 		fn := f.Name
 		suf := ""
 		l := ""
@@ -511,22 +1815,72 @@ func generateXdr(output io.Writer, s structInfo) {
 		if f.IsSlice {
 			suf = "<" + l + ">"
 		}
+		if f.Optional {
+			fn = "*" + fn
+		}
 
-		switch tn {
-		case "int8", "int16", "int32":
-			fmt.Fprintf(output, "\tint %s%s;\n", fn, suf)
-		case "uint8", "uint16", "uint32":
-			fmt.Fprintf(output, "\tunsigned int %s%s;\n", fn, suf)
-		case "int64":
-			fmt.Fprintf(output, "\thyper %s%s;\n", fn, suf)
-		case "uint64":
-			fmt.Fprintf(output, "\tunsigned hyper %s%s;\n", fn, suf)
-		case "string":
-			fmt.Fprintf(output, "\tstring %s<%s>;\n", fn, l)
-		case "[]byte":
-			fmt.Fprintf(output, "\topaque %s<%s>;\n", fn, l)
-		default:
-			fmt.Fprintf(output, "\t%s %s%s;\n", tn, fn, suf)
+		if f.IsMap {
+			// XDR has no maps: they are arrays of key/value pairs, sorted
+			// by key.
+			sl := ""
+			if f.Submax > 0 {
+				sl = strconv.Itoa(f.Submax)
+			}
+			value := xdrDecl(fieldInfo{FieldType: f.FieldType, Underlying: f.Underlying}, "value", "", sl)
+			fmt.Fprintf(output, "\tstruct { string key<%s>; %s; } %s<%s>;\n", l, value, fn, l)
+			continue
+		}
+		fmt.Fprintf(output, "\t%s;\n", xdrDecl(f, fn, suf, l))
+	}
+	fmt.Fprintln(output, "}")
+	fmt.Fprintln(output)
+}
+
+// xdrDecl returns the XDR language declaration of the field, named fn,
+// with suf following the name and l as the maximum length of strings and
+// opaque data.
+func xdrDecl(f fieldInfo, fn, suf, l string) string {
+	switch f.BasicType() {
+	case "int8", "int16", "int32":
+		return fmt.Sprintf("int %s%s", fn, suf)
+	case "uint8", "uint16", "uint32":
+		return fmt.Sprintf("unsigned int %s%s", fn, suf)
+	case "int64":
+		return fmt.Sprintf("hyper %s%s", fn, suf)
+	case "uint64":
+		return fmt.Sprintf("unsigned hyper %s%s", fn, suf)
+	case "float32":
+		return fmt.Sprintf("float %s%s", fn, suf)
+	case "float64":
+		return fmt.Sprintf("double %s%s", fn, suf)
+	case "string":
+		return fmt.Sprintf("string %s<%s>", fn, l)
+	case "[]byte":
+		return fmt.Sprintf("opaque %s<%s>", fn, l)
+	}
+	if f.FixedLen > 0 {
+		return fmt.Sprintf("opaque %s[%d]", fn, f.FixedLen)
+	}
+	return fmt.Sprintf("%s %s%s", f.BasicType(), fn, suf)
+}
+
+func generateUnionXdr(output io.Writer, s structInfo) {
+	d := s.Disc()
+	tn := d.FieldType
+	switch tn {
+	case "int8", "int16", "int32":
+		tn = "int"
+	case "uint8", "uint16", "uint32":
+		tn = "unsigned int"
+	}
+
+	fmt.Fprintf(output, "union %s switch (%s %s) {\n", s.Name, tn, d.Name)
+	for _, a := range s.Arms {
+		fmt.Fprintf(output, "case %s:\n", a.Case)
+		if a.Type == "" {
+			fmt.Fprintf(output, "\tvoid;\n")
+		} else {
+			fmt.Fprintf(output, "\t%s %s;\n", a.Type, s.Value().Name)
 		}
 	}
 	fmt.Fprintln(output, "}")
@@ -543,15 +1897,57 @@ func center(s string, w int) string {
 	return strings.Repeat(" ", l) + s + strings.Repeat(" ", r)
 }
 
-func inspector(structs *[]structInfo) func(ast.Node) bool {
+func inspector(structs *[]structInfo, enums *[]enumInfo, consts map[string][]string) func(ast.Node) bool {
 	return func(n ast.Node) bool {
 		switch n := n.(type) {
-		case *ast.TypeSpec:
-			switch t := n.Type.(type) {
-			case *ast.StructType:
-				name := n.Name.Name
-				fs := handleStruct(t)
-				*structs = append(*structs, structInfo{name, fs})
+		case *ast.GenDecl:
+			switch n.Tok {
+			case token.TYPE:
+				for _, spec := range n.Specs {
+					ts := spec.(*ast.TypeSpec)
+					doc := ts.Doc
+					if doc == nil && len(n.Specs) == 1 {
+						doc = n.Doc
+					}
+					switch t := ts.Type.(type) {
+					case *ast.StructType:
+						si := structInfo{
+							Name:   ts.Name.Name,
+							Fields: handleStruct(ts.Name.Name, t),
+						}
+						if hasDirective(doc, "xdr:union") {
+							si.IsUnion = true
+							si.Arms = handleUnion(si.Name, t)
+						}
+						si.Versioned = hasDirective(doc, "xdr:versioned") && !si.IsUnion && len(si.Fields) > 0
+						*structs = append(*structs, si)
+					case *ast.Ident:
+						if hasDirective(doc, "xdr:enum") {
+							*enums = append(*enums, enumInfo{Name: ts.Name.Name})
+						}
+					}
+				}
+
+			case token.CONST:
+				// A constant without type nor value repeats the previous
+				// one, as when using iota.
+				var typ string
+				for _, spec := range n.Specs {
+					vs := spec.(*ast.ValueSpec)
+					if id, ok := vs.Type.(*ast.Ident); ok {
+						typ = id.Name
+					} else if vs.Type != nil || len(vs.Values) > 0 {
+						typ = ""
+					}
+					if typ == "" {
+						continue
+					}
+					for _, name := range vs.Names {
+						if name.Name != "_" {
+							consts[typ] = append(consts[typ], name.Name)
+						}
+					}
+				}
 			}
 			return false
 		default:
@@ -560,27 +1956,139 @@ func inspector(structs *[]structInfo) func(ast.Node) bool {
 	}
 }
 
+func hasDirective(doc *ast.CommentGroup, directive string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(strings.TrimPrefix(c.Text, "//")) == directive {
+			return true
+		}
+	}
+	return false
+}
+
 func main() {
 	outputFile := flag.String("o", "", "Output file, blank for stdout")
+	testsFile := flag.String("tests", "", "Output file for round trip and fuzz tests of the generated types, blank for none")
+	pkgName := flag.String("package", "", "Package of the generated code for .x input, blank for the input file's base name")
+	floatEqual := flag.String("float-equal", "bits", "How Equal compares floats: \"bits\" for the same bits, or \"value\" for ==")
+	panicOnError := flag.Bool("panic", false, "Make UnmarshalXDR panic on malformed data instead of returning an error, for trusted input")
 	flag.Parse()
 	fname := flag.Arg(0)
+	if *floatEqual != "bits" && *floatEqual != "value" {
+		log.Fatalf("invalid -float-equal %q", *floatEqual)
+	}
+
+	// Schemas in the XDR language are translated into Go declarations,
+	// which are written out along with the code generated for them.
+	var src interface{}
+	var decls []byte
+	if filepath.Ext(fname) == ".x" {
+		bs, err := os.ReadFile(fname)
+		if err != nil {
+			log.Fatal(err)
+		}
+		decls, err = translateIDL(bs)
+		if err != nil {
+			log.Fatalf("%s: %v", fname, err)
+		}
+		if *pkgName == "" {
+			*pkgName = strings.TrimSuffix(filepath.Base(fname), ".x")
+		}
+		src = "package " + *pkgName + "\n\n" + string(decls)
+	}
 
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, fname, nil, parser.ParseComments)
+	f, err := parser.ParseFile(fset, fname, src, parser.ParseComments)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	var structs []structInfo
-	i := inspector(&structs)
+	var enums []enumInfo
+	consts := make(map[string][]string)
+	i := inspector(&structs, &enums, consts)
 	ast.Inspect(f, i)
 
+	pkg := typeCheck(fset, f)
+	isEnum := make(map[string]bool)
+	needStrconv := false
+	for i := range enums {
+		enums[i].Values = consts[enums[i].Name]
+		enums[i].HasString = hasMethod(pkg, enums[i].Name, "String")
+		enums[i].Panic = *panicOnError
+		isEnum[enums[i].Name] = true
+		needStrconv = needStrconv || !enums[i].HasString
+	}
+	for _, s := range structs {
+		for i := range s.Fields {
+			s.Fields[i].IsEnum = isEnum[s.Fields[i].FieldType]
+			if !s.Fields[i].IsEnum {
+				resolveNamed(&s.Fields[i], pkg)
+			}
+		}
+	}
+	minSizes := structMinSizes(structs)
+	local := make(map[string]bool)
+	for _, s := range structs {
+		local[s.Name] = true
+	}
+	imports := map[string]bool{"io": true, "strconv": needStrconv}
+	for i, s := range structs {
+		structs[i].HasEqual = hasMethod(pkg, s.Name, "Equal")
+		structs[i].Panic = *panicOnError
+		for i := range s.Fields {
+			if !s.Fields[i].IsBasic && !s.Fields[i].IsEnum {
+				s.Fields[i].StructSize = minSizes[s.Fields[i].FieldType]
+			}
+			s.Fields[i].EqualBy = equalBy(s.Fields[i], pkg, local, *floatEqual == "bits")
+			s.Fields[i].Owner = s.Name
+		}
+		for i := range s.Arms {
+			if s.Arms[i].Type != "" {
+				s.Arms[i].EqualBy = equalBy(fieldInfo{FieldType: s.Arms[i].Type}, pkg, local, false)
+			}
+		}
+		if structs[i].HasEqual {
+			continue
+		}
+		for _, f := range s.Fields {
+			imports["math"] = imports["math"] || f.EqualBy == "bits"
+			imports["reflect"] = imports["reflect"] || f.EqualBy == "reflect" && !s.IsUnion
+		}
+		for _, a := range s.Arms {
+			imports["reflect"] = imports["reflect"] || a.EqualBy == "reflect"
+		}
+	}
+	for _, s := range structs {
+		for _, f := range s.Fields {
+			imports["unsafe"] = imports["unsafe"] || (f.IsSlice || f.IsMap) && !s.IsUnion
+		}
+	}
+	var importList []string
+	for _, imp := range []string{"io", "math", "reflect", "strconv", "unsafe"} {
+		if imports[imp] {
+			importList = append(importList, imp)
+		}
+	}
+
 	buf := new(bytes.Buffer)
-	headerTpl.Execute(buf, map[string]string{"Package": f.Name.Name})
+	headerTpl.Execute(buf, map[string]interface{}{"Package": f.Name.Name, "Imports": importList})
+	if decls != nil {
+		fmt.Fprintf(buf, "\n%s", decls)
+	}
+	for _, e := range enums {
+		generateEnumCode(buf, e)
+	}
 	for _, s := range structs {
 		fmt.Fprintf(buf, "\n/*\n\n")
-		generateDiagram(buf, s)
-		generateXdr(buf, s)
+		if s.IsUnion {
+			generateUnionXdr(buf, s)
+		} else {
+			generateDiagram(buf, s)
+			generateXdr(buf, s)
+		}
 		fmt.Fprintf(buf, "*/\n")
 		generateCode(buf, s)
 	}
@@ -600,4 +2108,13 @@ func main() {
 		output = fd
 	}
 	output.Write(bs)
+
+	if *testsFile != "" {
+		fd, err := os.Create(*testsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		generateTests(fd, f.Name.Name, structs, enums, *panicOnError)
+		fd.Close()
+	}
 }
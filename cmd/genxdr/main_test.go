@@ -0,0 +1,143 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the generator instead of the tests when the test binary is
+// started by genxdr, so that its output and exit status can be checked.
+func TestMain(m *testing.M) {
+	if os.Getenv("GENXDR_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// genxdr runs the generator on src with the given flags, and returns its
+// output and whatever it logged, with an error if it failed.
+func genxdr(t *testing.T, src string, flags ...string) (string, string, error) {
+	t.Helper()
+	code, _, logged, err := run(t, src, false, flags)
+	return code, logged, err
+}
+
+// genxdrTests is like genxdr, but also generates tests, and returns them
+// after the output. A failure is fatal.
+func genxdrTests(t *testing.T, src string, flags ...string) (string, string) {
+	t.Helper()
+	code, tests, logged, err := run(t, src, true, flags)
+	if err != nil {
+		t.Fatal(err, logged)
+	}
+	return code, tests
+}
+
+func run(t *testing.T, src string, tests bool, flags []string) (string, string, string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	in := filepath.Join(dir, "input.go")
+	out := filepath.Join(dir, "output.go")
+	testsOut := filepath.Join(dir, "output_test.go")
+	if err := os.WriteFile(in, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := append(append([]string(nil), flags...), "-o", out)
+	if tests {
+		args = append(args, "-tests", testsOut)
+	}
+	cmd := exec.Command(os.Args[0], append(args, in)...)
+	cmd.Env = append(os.Environ(), "GENXDR_TEST_MAIN=1")
+	logged, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", "", string(logged), err
+	}
+	code, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testCode []byte
+	if tests {
+		if testCode, err = os.ReadFile(testsOut); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return string(code), string(testCode), string(logged), nil
+}
+
+func TestQualifiedOptionalField(t *testing.T) {
+	code, logged, err := genxdr(t, `package input
+
+import "example.com/other"
+
+type S struct {
+	P *other.T
+}
+`)
+	if err != nil {
+		t.Fatal(err, logged)
+	}
+	if !strings.Contains(code, "o.P = new(other.T)") {
+		t.Error("Expected the optional field to be allocated as other.T, got", code)
+	}
+}
+
+func TestUnsupportedFields(t *testing.T) {
+	for _, typ := range []string{"[][]byte", "[]*T", "[4]uint32", "*[]byte", "map[int]string", "map[string][]uint32", "chan int"} {
+		_, logged, err := genxdr(t, "package input\n\ntype T struct{}\n\ntype S struct {\n\tF "+typ+"\n}\n")
+		if err == nil {
+			t.Errorf("Expected %s to be rejected", typ)
+			continue
+		}
+		if exp := "struct S field F: unsupported type " + typ; !strings.Contains(logged, exp) {
+			t.Errorf("Expected %q, got %q", exp, logged)
+		}
+	}
+
+	code, logged, err := genxdr(t, "package input\n\ntype S struct {\n\tF [][]byte `xdr:\"-\"`\n\tN uint32\n}\n")
+	if err != nil {
+		t.Fatal(err, logged)
+	}
+	if strings.Contains(code, "o.F") {
+		t.Error("Expected the skipped field to be left out, got", code)
+	}
+}
+
+func TestRoundTripTestNames(t *testing.T) {
+	// Tests generated for different files of a package must not clash.
+	_, tests := genxdrTests(t, "package input\n\ntype A struct {\n\tN uint32\n}\n\ntype B struct {\n\tS string\n}\n")
+	for _, name := range []string{"TestXDRRoundTripA(", "TestXDRRoundTripB(", "FuzzUnmarshalA(", "FuzzUnmarshalB("} {
+		if !strings.Contains(tests, "func "+name) {
+			t.Errorf("Expected %s in %s", name, tests)
+		}
+	}
+	if strings.Contains(tests, "func TestXDRRoundTrip(") {
+		t.Error("Expected no shared round trip test, got", tests)
+	}
+}
+
+func TestPanicFuzzTargets(t *testing.T) {
+	// Fuzzing must not stop at the first malformed input with -panic.
+	src := "package input\n\ntype S struct {\n\tN uint32\n}\n"
+	code, tests := genxdrTests(t, src, "-panic")
+	if !strings.Contains(code, "panic(err)") {
+		t.Error("Expected UnmarshalXDR to panic, got", code)
+	}
+	if !strings.Contains(tests, "o.UnmarshalBinary(data)") || strings.Contains(tests, "o.UnmarshalXDR(data)") {
+		t.Error("Expected fuzzing through UnmarshalBinary, got", tests)
+	}
+
+	_, tests = genxdrTests(t, src)
+	if !strings.Contains(tests, "o.UnmarshalXDR(data)") {
+		t.Error("Expected fuzzing through UnmarshalXDR, got", tests)
+	}
+}
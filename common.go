@@ -5,16 +5,89 @@
 package xdr
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 var padBytes = []byte{0, 0, 0}
 
+// ErrNonZeroPadding is returned by a strict Unmarshaller when the padding
+// after a string or opaque value contains non-zero bytes.
+var ErrNonZeroPadding = errors.New("xdr: non-zero padding bytes")
+
+// ErrInvalidBool is returned by a strict Unmarshaller when a boolean is
+// encoded as something other than 0 or 1.
+var ErrInvalidBool = errors.New("xdr: invalid boolean value")
+
+// ErrNonZeroHighBytes is returned by a strict Unmarshaller when a uint8 or
+// uint16, which are encoded in four bytes, has non-zero unused high-order
+// bytes.
+var ErrNonZeroHighBytes = errors.New("xdr: non-zero high-order bytes")
+
+// ErrArrayMismatch is returned by an Unmarshaller when the elements of an
+// array iterated with UnmarshalArray don't match its element count.
+var ErrArrayMismatch = errors.New("xdr: array elements do not match count")
+
+// ErrTrailingData is wrapped by the error Unmarshaller.Finish returns when
+// data is left over after unmarshalling.
+var ErrTrailingData = errors.New("xdr: trailing data")
+
+// ErrUnalignedBlob is returned by Marshaller.MarshalXDRBlob when the data
+// passed to it cannot be XDR, as its length is not a multiple of four.
+var ErrUnalignedBlob = errors.New("xdr: XDR blob length is not a multiple of four")
+
+// ErrUnalignedData is wrapped by the error NewStrictUnmarshaller returns
+// when the length of its input is not a multiple of four, and by the error
+// Decoder.SeekTo returns for such an offset.
+var ErrUnalignedData = errors.New("xdr: data length is not a multiple of four")
+
+// ErrNotSeeker is returned by Decoder.SeekTo when the underlying reader does
+// not implement io.Seeker.
+var ErrNotSeeker = errors.New("xdr: reader does not support seeking")
+
+// ErrInvalidTime is returned by Unmarshaller.UnmarshalTime when the
+// nanoseconds of a time are out of range.
+var ErrInvalidTime = errors.New("xdr: time nanoseconds out of range")
+
+// ErrChecksum is returned by Decoder.DecodeSum when the checksum read does
+// not match the data decoded before it.
+var ErrChecksum = errors.New("xdr: checksum mismatch")
+
+// ErrAllocBudget is returned by an Unmarshaller when decoding would allocate
+// more than its AllocBudget.
+var ErrAllocBudget = errors.New("xdr: allocation budget exceeded")
+
+// ErrMaxDepth is returned by an Unmarshaller when values are nested deeper
+// than its MaxDepth.
+var ErrMaxDepth = errors.New("xdr: maximum nesting depth exceeded")
+
+// ErrNeedMore is wrapped by the error a Resumable Unmarshaller reports in
+// place of io.ErrUnexpectedEOF, when the data ends before the value being
+// unmarshalled does. See Unmarshaller.Resume.
+var ErrNeedMore = errors.New("xdr: more data needed")
+
+// ErrMapKeyOrder is returned by a strict Unmarshaller when the keys of a map
+// are not in strictly increasing order, as marshalling writes them, which
+// also rejects duplicate keys.
+var ErrMapKeyOrder = errors.New("xdr: map keys not in increasing order")
+
+// ErrInvalidUTF8 is returned by a Marshaller with ValidateUTF8 set when a
+// string is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("xdr: invalid UTF-8 string")
+
 // Padding returns the number of bytes that should be added to an item of length l
 // bytes to conform to the XDR padding standard. This function is used by the
-// generated marshalling code.
+// generated marshalling code. Negative lengths, which may come from a corrupt
+// size prefix on 32 bit builds, need no padding; whatever bounds check
+// rejects the length will then fail as usual.
 func Padding(l int) int {
+	if l < 0 {
+		return 0
+	}
 	d := l % 4
 	if d == 0 {
 		return 0
@@ -22,10 +95,129 @@ func Padding(l int) int {
 	return 4 - d
 }
 
-// ElementSizeExceeded returns an error describing the violated size
-// constraint. This function is used by the generated marshalling code.
+// BytesSize returns the XDR encoded size of a byte slice of length l,
+// including its size prefix and padding.
+func BytesSize(l int) int {
+	return 4 + l + Padding(l)
+}
+
+// StringSize returns the XDR encoded size of s, including its size prefix
+// and padding.
+func StringSize(s string) int {
+	return BytesSize(len(s))
+}
+
+// Uint32Size returns the XDR encoded size of a uint32. Bools and all integer
+// types of 32 bits or less share this size.
+func Uint32Size() int {
+	return 4
+}
+
+// Uint64Size returns the XDR encoded size of a uint64.
+func Uint64Size() int {
+	return 8
+}
+
+// ErrElementSizeExceeded is matched by errors.Is for every ElementSizeError.
+var ErrElementSizeExceeded = errors.New("xdr: element size exceeded")
+
+// ElementSizeError describes a string, opaque or array field that is longer
+// than its size limit allows. Size is wide enough to hold any size prefix,
+// including the ones that do not fit an int on 32 bit builds.
+type ElementSizeError struct {
+	Field string
+	Size  int64
+	Max   int
+}
+
+func (e *ElementSizeError) Error() string {
+	return fmt.Sprintf("%s exceeds size limit; %d > %d", e.Field, e.Size, e.Max)
+}
+
+// Is reports whether target is ErrElementSizeExceeded.
+func (e *ElementSizeError) Is(target error) bool {
+	return target == ErrElementSizeExceeded
+}
+
+// ElementSizeExceeded returns an *ElementSizeError describing the violated
+// size constraint. This function is used by the generated marshalling code.
 func ElementSizeExceeded(field string, size, limit int) error {
-	return fmt.Errorf("%s exceeds size limit; %d > %d", field, size, limit)
+	return &ElementSizeError{Field: field, Size: int64(size), Max: limit}
+}
+
+// CheckLength returns the length l read from the size prefix of field as an
+// int, or an *ElementSizeError if it is above max, when max is positive, or
+// above math.MaxInt32 in any case. As lengths are checked before they are
+// converted, the outcome is the same whatever the width of int: a length with
+// its high bit set is rejected on 64 bit builds too, rather than turning
+// negative on 32 bit ones only. This function is used by the generated
+// marshalling code.
+func CheckLength(field string, l uint32, max int) (int, error) {
+	limit := max
+	if limit <= 0 || limit > math.MaxInt32 {
+		limit = math.MaxInt32
+	}
+	if l > uint32(limit) {
+		return 0, &ElementSizeError{Field: field, Size: int64(l), Max: limit}
+	}
+	return int(l), nil
+}
+
+// checkEncodedLength returns an *ElementSizeError if l is not a length that
+// CheckLength accepts, that is if it is above math.MaxInt32, so that nothing
+// is encoded that would fail to decode, on 32 or 64 bit builds alike.
+func checkEncodedLength(field string, l int) error {
+	if l < 0 || int64(l) > math.MaxInt32 {
+		return &ElementSizeError{Field: field, Size: int64(l), Max: math.MaxInt32}
+	}
+	return nil
+}
+
+// InvalidEnumValue returns an error describing a value that is not part of
+// the named enum. This function is used by the generated marshalling code.
+func InvalidEnumValue(enum string, v int32) error {
+	return fmt.Errorf("%d is not a valid %s value", v, enum)
+}
+
+// InvalidUnionArm returns an error describing a union whose discriminant
+// has no matching arm, or whose arm value does not match the discriminant.
+// This function is used by the generated marshalling code.
+func InvalidUnionArm(union string, discriminant interface{}) error {
+	return fmt.Errorf("invalid %s arm for discriminant %v", union, discriminant)
+}
+
+// FieldTag holds the options of an xdr struct tag.
+type FieldTag struct {
+	Skip bool // the field is not encoded, from `xdr:"-"`
+	Max  int  // size limit for strings and slices, from `xdr:"max=N"`
+}
+
+// ParseFieldTag parses the value of an xdr struct tag, such as "max=255" or
+// "-". This function is used by both genxdr and the reflection based Marshal
+// and Unmarshal, so that they agree on field options.
+func ParseFieldTag(tag string) (FieldTag, error) {
+	var ft FieldTag
+	if tag == "" {
+		return ft, nil
+	}
+	if tag == "-" {
+		ft.Skip = true
+		return ft, nil
+	}
+
+	for _, opt := range strings.Split(tag, ",") {
+		v, ok := strings.CutPrefix(strings.TrimSpace(opt), "max=")
+		if !ok {
+			return FieldTag{}, fmt.Errorf("xdr: unknown tag option %q", opt)
+		}
+		max, err := strconv.Atoi(v)
+		if err != nil || max < 0 {
+			return FieldTag{}, fmt.Errorf("xdr: invalid max %q in tag", v)
+		}
+		ft.Max = max
+	}
+
+	return ft, nil
 }
 
 // Sizer is a value that can return its XDR serialized size.
@@ -34,26 +226,38 @@ type Sizer interface {
 }
 
 // SizeOfSlice returns the XDR encoded size of the given []T. Supported types
-// for T are string, []byte and types implementing Sizer. SizeOfSlice
-// panics if the parameter is not a slice or if T is not one of the supported
-// types. This function is used by the generated marshalling code.
+// for T are string, []byte, types based on them and types implementing
+// Sizer. SizeOfSlice panics if the parameter is not a slice or if T is not
+// one of the supported types. This function is used by the generated
+// marshalling code.
 func SizeOfSlice(ss interface{}) int {
 	l := 0
 	switch ss := ss.(type) {
 	case []string:
 		for _, s := range ss {
-			l += 4 + len(s) + Padding(len(s))
+			l += StringSize(s)
 		}
 
 	case [][]byte:
 		for _, s := range ss {
-			l += 4 + len(s) + Padding(len(s))
+			l += BytesSize(len(s))
 		}
 
 	default:
 		v := reflect.ValueOf(ss)
 		for i := 0; i < v.Len(); i++ {
-			l += v.Index(i).Interface().(Sizer).XDRSize()
+			switch e := v.Index(i); {
+			case e.Kind() == reflect.String:
+				l += BytesSize(e.Len())
+			case e.Kind() == reflect.Slice && e.Type().Elem().Kind() == reflect.Uint8:
+				l += BytesSize(e.Len())
+			case e.CanAddr():
+				// Going through a pointer avoids copying the element into
+				// an interface, which allocates.
+				l += e.Addr().Interface().(Sizer).XDRSize()
+			default:
+				l += e.Interface().(Sizer).XDRSize()
+			}
 		}
 	}
 
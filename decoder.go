@@ -0,0 +1,412 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"os"
+	"time"
+)
+
+// Decoder reads XDR encoded values from an io.Reader. Reads are buffered
+// internally. Once an error has occurred, all further Decode... calls return
+// that same error.
+//
+// A Decode... call returns io.EOF only if the stream ended before the first
+// byte of its value, and io.ErrUnexpectedEOF if it ended part way through,
+// padding included. Values made of several elements should be decoded with
+// DecodeRecord, so that the stream ending between two of their elements is
+// not mistaken for a clean end.
+type Decoder struct {
+	r   *bufio.Reader
+	src io.Reader
+	h   hash.Hash
+	buf [8]byte
+	err error
+	max int
+	n   int64
+}
+
+// deadlineReader is implemented by readers, such as net.Conn, whose blocked
+// reads can be interrupted by setting a deadline.
+type deadlineReader interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r), src: r}
+}
+
+// BytesRead returns the number of bytes decoded so far, including size
+// prefixes and padding. Data read ahead from the underlying reader into the
+// Decoder's buffer is not counted until it is decoded.
+func (d *Decoder) BytesRead() int64 {
+	return d.n
+}
+
+// SetMaxElementSize limits the length of any string or byte slice read from
+// the stream to n bytes. Longer elements fail with an ElementSizeExceeded
+// error before any memory is allocated for them. A value of zero removes the
+// limit.
+func (d *Decoder) SetMaxElementSize(n int) {
+	d.max = n
+}
+
+// SetHash makes the Decoder write all data it decodes from now on to h as
+// well, so that a checksum over it is verified in the same pass. A nil h
+// stops hashing. See Encoder.SetHash.
+func (d *Decoder) SetHash(h hash.Hash) {
+	d.h = h
+}
+
+// Sum appends the current sum of the hash set by SetHash to b and returns
+// the result.
+func (d *Decoder) Sum(b []byte) []byte {
+	return d.h.Sum(b)
+}
+
+// DecodeSum reads a checksum written by Encoder.EncodeSum and compares it to
+// the sum of the hash set by SetHash, failing with ErrChecksum if they
+// differ. The hash is reset, so that the next checksum covers the data
+// decoded after this one.
+func (d *Decoder) DecodeSum() error {
+	if d.h == nil {
+		panic("xdr: Decoder.DecodeSum without a hash")
+	}
+
+	h := d.h
+	d.h = nil
+	want := h.Sum(nil)
+	got, err := d.DecodeFixedOpaque(len(want))
+	h.Reset()
+	d.h = h
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(got, want) {
+		d.err = ErrChecksum
+	}
+	return d.err
+}
+
+// SeekTo moves the Decoder to offset bytes from the start of the underlying
+// reader, which must implement io.Seeker, discarding any data read ahead.
+// The offset must be a multiple of four, as every XDR value is, so that
+// random access into large files need not read what lies before the value.
+// A successful SeekTo clears the error from earlier reads, and BytesRead then
+// counts from the start of the reader.
+func (d *Decoder) SeekTo(offset int64) error {
+	s, ok := d.src.(io.Seeker)
+	if !ok {
+		return ErrNotSeeker
+	}
+	if offset < 0 || offset%4 != 0 {
+		return fmt.Errorf("%w: offset %d", ErrUnalignedData, offset)
+	}
+	if _, err := s.Seek(offset, io.SeekStart); err != nil {
+		d.err = err
+		return err
+	}
+
+	d.r.Reset(d.src)
+	d.err = nil
+	d.n = offset
+	return nil
+}
+
+// DecodeRecord calls fn to decode one record, such as a struct, made of
+// several elements. If the stream ends before fn has read anything, the
+// error is io.EOF, marking the clean end of a stream of records. If it ends
+// after fn has read part of the record, the error is io.ErrUnexpectedEOF,
+// even if the end fell between two elements.
+//
+//	for {
+//		err := d.DecodeRecord(func(d *xdr.Decoder) error {
+//			// decode the fields of a record
+//		})
+//		if err == io.EOF {
+//			break
+//		}
+//		// handle err and the record
+//	}
+func (d *Decoder) DecodeRecord(fn func(*Decoder) error) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	start := d.n
+	err := fn(d)
+	if d.n > start && (err == io.EOF || d.err == io.EOF) {
+		d.err = io.ErrUnexpectedEOF
+		return d.err
+	}
+	return err
+}
+
+// DecodeRaw returns l bytes from the stream, without a size prefix or
+// padding.
+func (d *Decoder) DecodeRaw(l int) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if l < 0 {
+		d.err = ElementSizeExceeded("raw field", l, 0)
+		return nil, d.err
+	}
+
+	buf := make([]byte, l)
+	d.start(buf)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return buf, nil
+}
+
+// DecodeFixedOpaque returns n bytes from the stream, without a size prefix,
+// and skips the padding following them.
+func (d *Decoder) DecodeFixedOpaque(n int) ([]byte, error) {
+	buf, err := d.DecodeRaw(n)
+	if err != nil {
+		return nil, err
+	}
+
+	d.cont(d.buf[:Padding(n)])
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return buf, nil
+}
+
+// DecodeString returns a string from the stream.
+func (d *Decoder) DecodeString() (string, error) {
+	return d.DecodeStringMax(0)
+}
+
+// DecodeStringMax returns a string up to a max length from the stream.
+func (d *Decoder) DecodeStringMax(max int) (string, error) {
+	buf, err := d.DecodeBytesMax(max)
+	if err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// DecodeBytes returns a byte slice from the stream.
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	return d.DecodeBytesMax(0)
+}
+
+// DecodeBytesMax returns a byte slice up to a max length from the stream.
+// The limit set by SetMaxElementSize applies if it is stricter.
+func (d *Decoder) DecodeBytesMax(max int) ([]byte, error) {
+	if d.max > 0 && (max <= 0 || d.max < max) {
+		max = d.max
+	}
+
+	d.start(d.buf[:4])
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	l, err := CheckLength("bytes field", Uint32BE(d.buf[:]), max)
+	if err != nil {
+		d.err = err
+		return nil, d.err
+	}
+	if l == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, l)
+	d.cont(buf)
+	d.cont(d.buf[:Padding(l)])
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return buf, nil
+}
+
+// DecodeBytesContext is like DecodeBytes, but gives up when ctx is done. A
+// blocked read can only be interrupted if the underlying reader supports read
+// deadlines, as net.Conn does; for other readers ctx is checked before reading
+// only. If ctx ends part way through the value, the stream is left in an
+// unknown state and the Decoder keeps returning the context's error.
+//
+// A read deadline set on the reader by the caller is left alone, unless ctx
+// has a deadline or is cancelled during the read: the read deadline is then
+// replaced, and cleared once the value is decoded, as there is no way to read
+// back the previous one.
+func (d *Decoder) DecodeBytesContext(ctx context.Context) ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stop := d.watch(ctx)
+	bs, err := d.DecodeBytes()
+	stop()
+	if err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			d.err = cerr
+		} else if _, ok := ctx.Deadline(); ok && errors.Is(err, os.ErrDeadlineExceeded) {
+			// The reader's deadline may expire just before the context's.
+			d.err = context.DeadlineExceeded
+		}
+		return nil, d.err
+	}
+
+	return bs, err
+}
+
+// watch applies the deadline of ctx to the underlying reader, if it supports
+// read deadlines, and interrupts reads when ctx is done. The returned
+// function stops watching and clears the deadline, if watch set one.
+func (d *Decoder) watch(ctx context.Context) func() {
+	dr, ok := d.src.(deadlineReader)
+	if !ok {
+		return func() {}
+	}
+	dl, hasDeadline := ctx.Deadline()
+	if !hasDeadline && ctx.Done() == nil {
+		// Nothing to watch, as for context.Background.
+		return func() {}
+	}
+	installed := hasDeadline
+	if installed {
+		dr.SetReadDeadline(dl)
+	}
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			// A deadline in the past makes pending reads fail immediately.
+			dr.SetReadDeadline(time.Unix(1, 0))
+			installed = true
+		case <-done:
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-exited
+		if installed {
+			dr.SetReadDeadline(time.Time{})
+		}
+	}
+}
+
+// DecodeBool returns a bool from the stream.
+func (d *Decoder) DecodeBool() (bool, error) {
+	v, err := d.DecodeUint8()
+	return v != 0, err
+}
+
+// DecodeUint8 returns a uint8 from the stream.
+func (d *Decoder) DecodeUint8() (uint8, error) {
+	v, err := d.DecodeUint32()
+	return uint8(v), err
+}
+
+// DecodeUint16 returns a uint16 from the stream.
+func (d *Decoder) DecodeUint16() (uint16, error) {
+	v, err := d.DecodeUint32()
+	return uint16(v), err
+}
+
+// DecodeUint32 returns a uint32 from the stream.
+func (d *Decoder) DecodeUint32() (uint32, error) {
+	d.start(d.buf[:4])
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	return Uint32BE(d.buf[:]), nil
+}
+
+// DecodeUint64 returns a uint64 from the stream.
+func (d *Decoder) DecodeUint64() (uint64, error) {
+	d.start(d.buf[:8])
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	return Uint64BE(d.buf[:]), nil
+}
+
+// DecodeInt32 returns an int32 from the stream.
+func (d *Decoder) DecodeInt32() (int32, error) {
+	v, err := d.DecodeUint32()
+	return int32(v), err
+}
+
+// DecodeInt64 returns an int64 from the stream.
+func (d *Decoder) DecodeInt64() (int64, error) {
+	v, err := d.DecodeUint64()
+	return int64(v), err
+}
+
+// DecodeFloat32 returns a float32 from the stream.
+func (d *Decoder) DecodeFloat32() (float32, error) {
+	v, err := d.DecodeUint32()
+	return math.Float32frombits(v), err
+}
+
+// DecodeFloat64 returns a float64 from the stream.
+func (d *Decoder) DecodeFloat64() (float64, error) {
+	v, err := d.DecodeUint64()
+	return math.Float64frombits(v), err
+}
+
+// start fills p with the first bytes of a new element. The stream may end
+// cleanly here, in which case the error is io.EOF.
+func (d *Decoder) start(p []byte) {
+	if d.err != nil {
+		return
+	}
+	n, err := io.ReadFull(d.r, p)
+	d.n += int64(n)
+	if d.h != nil {
+		d.h.Write(p[:n])
+	}
+	if err != nil {
+		d.err = err
+	}
+}
+
+// cont fills p with the continuation of an element. The stream must not end
+// here, so io.EOF is reported as io.ErrUnexpectedEOF.
+func (d *Decoder) cont(p []byte) {
+	if d.err != nil {
+		return
+	}
+	n, err := io.ReadFull(d.r, p)
+	d.n += int64(n)
+	if d.h != nil {
+		d.h.Write(p[:n])
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		d.err = err
+	}
+}
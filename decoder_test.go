@@ -0,0 +1,346 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"dario.cat/xdr"
+)
+
+func TestDecoder(t *testing.T) {
+	var s TestStruct
+	s.UI32 = 42
+	s.S = "hello"
+	s.BS = []byte{1, 2, 3}
+	bs := s.MustMarshalXDR()
+
+	d := xdr.NewDecoder(bytes.NewReader(bs))
+	if v, err := d.DecodeBool(); err != nil || v != s.B {
+		t.Fatal("B:", v, err)
+	}
+	if v, err := d.DecodeUint64(); err != nil || int(v) != s.I {
+		t.Fatal("I:", v, err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := d.DecodeUint32(); err != nil {
+			t.Fatal("I8..UI16:", err)
+		}
+	}
+	if v, err := d.DecodeInt32(); err != nil || v != s.I32 {
+		t.Fatal("I32:", v, err)
+	}
+	if v, err := d.DecodeUint32(); err != nil || v != s.UI32 {
+		t.Fatal("UI32:", v, err)
+	}
+	if v, err := d.DecodeInt64(); err != nil || v != s.I64 {
+		t.Fatal("I64:", v, err)
+	}
+	if v, err := d.DecodeUint64(); err != nil || v != s.UI64 {
+		t.Fatal("UI64:", v, err)
+	}
+	if v, err := d.DecodeBytes(); err != nil || !bytes.Equal(v, s.BS) {
+		t.Fatal("BS:", v, err)
+	}
+	if v, err := d.DecodeString(); err != nil || v != s.S {
+		t.Fatal("S:", v, err)
+	}
+	if v, err := d.DecodeFixedOpaque(len(s.C)); err != nil || !bytes.Equal(v, s.C[:]) {
+		t.Fatal("C:", v, err)
+	}
+}
+
+func TestDecoderEOF(t *testing.T) {
+	d := xdr.NewDecoder(bytes.NewReader([]byte{0, 0, 0, 8, 'a', 'b'}))
+	if _, err := d.DecodeBytes(); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if _, err := d.DecodeUint32(); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected latched io.ErrUnexpectedEOF, got", err)
+	}
+
+	d = xdr.NewDecoder(bytes.NewReader(nil))
+	if _, err := d.DecodeUint32(); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+
+	// Any part of an element, size prefix, data or padding, is a truncation.
+	for _, bs := range [][]byte{{0, 0}, {0, 0, 0, 2}, {0, 0, 0, 2, 'a', 'b'}, {0, 0, 0, 2, 'a', 'b', 0}} {
+		d = xdr.NewDecoder(bytes.NewReader(bs))
+		if _, err := d.DecodeBytes(); err != io.ErrUnexpectedEOF {
+			t.Errorf("%x: expected io.ErrUnexpectedEOF, got %v", bs, err)
+		}
+	}
+	d = xdr.NewDecoder(bytes.NewReader([]byte{0, 0, 0}))
+	if _, err := d.DecodeUint32(); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	type record struct {
+		S string
+		N uint32
+	}
+	decode := func(r *record) func(*xdr.Decoder) error {
+		return func(d *xdr.Decoder) error {
+			var err error
+			if r.S, err = d.DecodeString(); err != nil {
+				return err
+			}
+			r.N, err = d.DecodeUint32()
+			return err
+		}
+	}
+
+	bs, _ := xdr.Marshal(record{"a", 1})
+	stream := append(append([]byte(nil), bs...), bs...)
+	d := xdr.NewDecoder(bytes.NewReader(stream))
+	var r record
+	for i := 0; i < 2; i++ {
+		if err := d.DecodeRecord(decode(&r)); err != nil || r != (record{"a", 1}) {
+			t.Fatalf("Unexpected %+v (%v)", r, err)
+		}
+	}
+	if err := d.DecodeRecord(decode(&r)); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+
+	// The stream ending between the fields of a record is a truncation.
+	d = xdr.NewDecoder(bytes.NewReader(stream[:len(bs)+8]))
+	d.DecodeRecord(decode(&r))
+	if err := d.DecodeRecord(decode(&r)); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if _, err := d.DecodeUint32(); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected latched io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestDecoderMaxElementSize(t *testing.T) {
+	d := xdr.NewDecoder(bytes.NewReader([]byte{0x7f, 0xff, 0xff, 0xff}))
+	d.SetMaxElementSize(1024)
+	if _, err := d.DecodeBytes(); err == nil || err == io.ErrUnexpectedEOF {
+		t.Fatal("Expected size limit error, got", err)
+	}
+
+	d = xdr.NewDecoder(bytes.NewReader([]byte{0, 0, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 0, 0}))
+	d.SetMaxElementSize(8)
+	if _, err := d.DecodeStringMax(4); err == nil {
+		t.Fatal("Expected explicit max to apply")
+	}
+
+	d = xdr.NewDecoder(bytes.NewReader([]byte{0, 0, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 0, 0}))
+	d.SetMaxElementSize(5)
+	if v, err := d.DecodeStringMax(16); err != nil || v != "hello" {
+		t.Fatal("Unexpected result", v, err)
+	}
+}
+
+func TestLimitedUnmarshaller(t *testing.T) {
+	r := bytes.NewReader([]byte{0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3})
+	u, err := xdr.NewLimitedUnmarshaller(r, 8)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if v := u.UnmarshalUint32(); v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+	if v := u.UnmarshalUint32(); v != 2 {
+		t.Errorf("Expected 2, got %d", v)
+	}
+	u.UnmarshalUint32()
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF past the frame, got", u.Error)
+	}
+
+	if _, err := xdr.NewLimitedUnmarshaller(r, 8); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if _, err := xdr.NewLimitedUnmarshaller(r, 4); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+}
+
+func TestUnmarshallerFromReader(t *testing.T) {
+	data := []byte{0, 0, 0, 1, 0, 0, 0, 2}
+
+	u, err := xdr.NewUnmarshallerFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if v := u.UnmarshalUint64(); v != 1<<32|2 {
+		t.Errorf("Expected %d, got %d", uint64(1<<32|2), v)
+	}
+	if err := u.Finish(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	if _, err := xdr.NewUnmarshallerFromReaderMax(bytes.NewReader(data), 8); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if _, err := xdr.NewUnmarshallerFromReaderMax(bytes.NewReader(data), 7); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected xdr.ErrElementSizeExceeded, got", err)
+	}
+}
+
+func TestDecodeBytesContext(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		e := xdr.NewEncoder(server)
+		e.EncodeBytes([]byte("first"))
+		e.Flush()
+	}()
+
+	d := xdr.NewDecoder(client)
+	bs, err := d.DecodeBytesContext(context.Background())
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if string(bs) != "first" {
+		t.Errorf("Expected \"first\", got %q", bs)
+	}
+
+	// Nothing more is written, so the read blocks until cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := d.DecodeBytesContext(ctx); err != context.Canceled {
+		t.Fatal("Expected context.Canceled, got", err)
+	}
+	if _, err := d.DecodeUint32(); err != context.Canceled {
+		t.Fatal("Expected the error to be latched, got", err)
+	}
+
+	// Readers without deadlines only check the context up front.
+	d = xdr.NewDecoder(bytes.NewReader([]byte{0, 0, 0, 0}))
+	if _, err := d.DecodeBytesContext(ctx); err != context.Canceled {
+		t.Fatal("Expected context.Canceled, got", err)
+	}
+	if _, err := d.DecodeBytesContext(context.Background()); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+}
+
+func TestDecodeBytesContextDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	d := xdr.NewDecoder(client)
+	if _, err := d.DecodeBytesContext(ctx); err != context.DeadlineExceeded {
+		t.Fatal("Expected context.DeadlineExceeded, got", err)
+	}
+}
+
+func TestDecodeBytesContextKeepsDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		e := xdr.NewEncoder(server)
+		e.EncodeBytes([]byte("first"))
+		e.Flush()
+	}()
+
+	// The caller's deadline outlives a context without one.
+	client.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	d := xdr.NewDecoder(client)
+	if _, err := d.DecodeBytesContext(context.Background()); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := d.DecodeBytes()
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Fatal("Expected os.ErrDeadlineExceeded, got", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the read deadline to be kept")
+	}
+}
+
+// zeroConn reads endless zeros, and accepts read deadlines.
+type zeroConn struct{}
+
+func (zeroConn) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+func (zeroConn) SetReadDeadline(time.Time) error { return nil }
+
+func TestDecodeBytesContextBackground(t *testing.T) {
+	// A context that can never be done costs nothing to watch.
+	d := xdr.NewDecoder(zeroConn{})
+	ctx := context.Background()
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := d.DecodeBytesContext(ctx); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+	})
+	if allocs != 0 {
+		t.Error("Expected no allocations, got", allocs)
+	}
+}
+
+func TestDecoderSeekTo(t *testing.T) {
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	e.EncodeString("first")
+	e.EncodeUint64(42)
+	e.EncodeString("last")
+	e.Flush()
+
+	d := xdr.NewDecoder(bytes.NewReader(buf.Bytes()))
+	if err := d.SeekTo(12); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if v, err := d.DecodeUint64(); err != nil || v != 42 {
+		t.Fatal("Expected 42, got", v, err)
+	}
+	if d.BytesRead() != 20 {
+		t.Error("Expected 20 bytes read, got", d.BytesRead())
+	}
+
+	// Seeking back clears the error at the end of the stream.
+	d.DecodeString()
+	if _, err := d.DecodeUint32(); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+	if err := d.SeekTo(0); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if v, err := d.DecodeString(); err != nil || v != "first" {
+		t.Fatal("Expected \"first\", got", v, err)
+	}
+
+	if err := d.SeekTo(6); !errors.Is(err, xdr.ErrUnalignedData) {
+		t.Error("Expected ErrUnalignedData, got", err)
+	}
+	d = xdr.NewDecoder(&buf)
+	if err := d.SeekTo(0); err != xdr.ErrNotSeeker {
+		t.Error("Expected ErrNotSeeker, got", err)
+	}
+}
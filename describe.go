@@ -0,0 +1,178 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DescribeType returns the XDR layout Marshal and Unmarshal use for values of
+// the type of v, in the XDR language, for debugging. A struct is shown field
+// by field, in the way genxdr documents the types it generates code for:
+//
+//	struct Header {
+//		unsigned int Magic;
+//		string Name<64>;
+//		Entry Entries<>;
+//	}
+//
+// followed by the structs it refers to, each described once. Only the type of
+// v is used, and a pointer is described as the type it points to. Types that
+// encode themselves are described by their fields too, which is their layout
+// when they are generated by genxdr, as it follows the same rules; hand-written
+// encodings may differ. int8 and int16 values are encoded as their unsigned
+// bit patterns, not sign-extended, and are shown as unsigned ints. A type
+// other than a struct is described as a typedef named value.
+func DescribeType(v interface{}) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return ""
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	d := &describer{seen: make(map[reflect.Type]bool)}
+	if t.Kind() == reflect.Struct && t != timeType {
+		d.queue = append(d.queue, t)
+		d.seen[t] = true
+	} else {
+		fmt.Fprintf(&d.sb, "typedef %s;\n", d.decl(t, "value", 0))
+	}
+
+	for i := 0; i < len(d.queue); i++ {
+		if d.sb.Len() > 0 {
+			d.sb.WriteString("\n")
+		}
+		d.describeStruct(d.queue[i])
+	}
+	return d.sb.String()
+}
+
+// describer accumulates the description of a type, and the named structs
+// still to describe.
+type describer struct {
+	sb    strings.Builder
+	queue []reflect.Type
+	seen  map[reflect.Type]bool
+}
+
+// describeStruct writes the declaration of the named struct type t.
+func (d *describer) describeStruct(t reflect.Type) {
+	fmt.Fprintf(&d.sb, "struct %s {\n", t.Name())
+	d.fields(t)
+	d.sb.WriteString("}\n")
+}
+
+// fields writes the declarations of the encoded fields of the struct type t,
+// one per line.
+func (d *describer) fields(t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		ft, err := parseTag(f)
+		if err != nil {
+			fmt.Fprintf(&d.sb, "\t/* %s */\n", err)
+			continue
+		}
+		if ft.Skip {
+			continue
+		}
+		fmt.Fprintf(&d.sb, "\t%s;\n", d.decl(f.Type, f.Name, ft.Max))
+	}
+}
+
+// decl returns the XDR language declaration of a value of type t named name,
+// with max limiting its length as the max tag does. Named structs it refers
+// to are queued to be described.
+func (d *describer) decl(t reflect.Type, name string, max int) string {
+	l := ""
+	if max > 0 {
+		l = strconv.Itoa(max)
+	}
+
+	if t == timeType {
+		return fmt.Sprintf("struct { hyper seconds; unsigned int nseconds; } %s", name)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool " + name
+	case reflect.Int32:
+		return "int " + name
+	case reflect.Int8, reflect.Int16, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		// Marshal does not sign-extend int8 and int16 values.
+		return "unsigned int " + name
+	case reflect.Int, reflect.Int64:
+		return "hyper " + name
+	case reflect.Uint, reflect.Uint64:
+		return "unsigned hyper " + name
+	case reflect.Float32:
+		return "float " + name
+	case reflect.Float64:
+		return "double " + name
+
+	case reflect.String:
+		return fmt.Sprintf("string %s<%s>", name, l)
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("opaque %s<%s>", name, l)
+		}
+		return d.element(t.Elem(), fmt.Sprintf("%s<%s>", name, l))
+
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("opaque %s[%d]", name, t.Len())
+		}
+		return d.element(t.Elem(), fmt.Sprintf("%s[%d]", name, t.Len()))
+
+	case reflect.Ptr:
+		return d.decl(t.Elem(), "*"+name, max)
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			var sb strings.Builder
+			sb.WriteString("struct {")
+			for i := 0; i < t.NumField(); i++ {
+				f := t.Field(i)
+				ft, err := parseTag(f)
+				if f.PkgPath != "" || ft.Skip || err != nil {
+					continue
+				}
+				fmt.Fprintf(&sb, " %s;", d.decl(f.Type, f.Name, ft.Max))
+			}
+			sb.WriteString(" } ")
+			sb.WriteString(name)
+			return sb.String()
+		}
+		if !d.seen[t] {
+			d.seen[t] = true
+			d.queue = append(d.queue, t)
+		}
+		return fmt.Sprintf("%s %s", t.Name(), name)
+	}
+
+	return fmt.Sprintf("/* unsupported type %s */ %s", t, name)
+}
+
+// element returns the declaration of an array or slice, named name including
+// its length, of elements of type t. XDR has no arrays of strings, arrays or
+// optional data, so those are shown as arrays of a struct holding the
+// element, which has the same encoding.
+func (d *describer) element(t reflect.Type, name string) string {
+	switch {
+	case t == timeType:
+	case t.Kind() == reflect.String, t.Kind() == reflect.Slice,
+		t.Kind() == reflect.Array, t.Kind() == reflect.Ptr:
+		return fmt.Sprintf("struct { %s; } %s", d.decl(t, "value", 0), name)
+	}
+	return d.decl(t, name, 0)
+}
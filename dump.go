@@ -0,0 +1,58 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dump returns a hex dump of XDR encoded data for debugging, with one 4-byte
+// word per line. Each line shows the offset of the word, its bytes in hex and
+// as printable ASCII, and its value as an uint32, which makes length prefixes
+// and misaligned data easy to spot:
+//
+//	00000000  00 00 00 05  |....|  5
+//	00000004  68 65 6c 6c  |hell|  1751477356
+//	00000008  6f 00 00 00  |o...|  1862270976
+//
+// A trailing partial word is shown without a value.
+func Dump(data []byte) string {
+	var sb strings.Builder
+	for off := 0; off < len(data); off += 4 {
+		word := data[off:]
+		if len(word) > 4 {
+			word = word[:4]
+		}
+
+		fmt.Fprintf(&sb, "%08x ", off)
+		for i := 0; i < 4; i++ {
+			if i < len(word) {
+				fmt.Fprintf(&sb, " %02x", word[i])
+			} else {
+				sb.WriteString("   ")
+			}
+		}
+
+		sb.WriteString("  |")
+		for _, b := range word {
+			if b >= 0x20 && b < 0x7f {
+				sb.WriteByte(b)
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString(strings.Repeat(" ", 4-len(word)))
+		sb.WriteString("|")
+
+		if len(word) == 4 {
+			v := Uint32BE(word)
+			fmt.Fprintf(&sb, "  %d", v)
+		}
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"testing"
+
+	"dario.cat/xdr"
+)
+
+func TestDump(t *testing.T) {
+	data := []byte{0, 0, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 0, 0, 0xff, 0xfe}
+	exp := "00000000  00 00 00 05  |....|  5\n" +
+		"00000004  68 65 6c 6c  |hell|  1751477356\n" +
+		"00000008  6f 00 00 00  |o...|  1862270976\n" +
+		"0000000c  ff fe        |..  |\n"
+	if s := xdr.Dump(data); s != exp {
+		t.Errorf("Unexpected dump:\n%s\nexpected:\n%s", s, exp)
+	}
+
+	if s := xdr.Dump(nil); s != "" {
+		t.Errorf("Expected empty dump, got %q", s)
+	}
+}
@@ -6,12 +6,20 @@ package xdr_test
 
 import (
 	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
 	"io"
 	"log"
+	"math"
 	"math/rand"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"testing/quick"
+	"time"
+	"unsafe"
 
 	"dario.cat/xdr"
 )
@@ -137,6 +145,10 @@ func (u *Opaque) MarshalXDRInto(m *xdr.Marshaller) error {
 	return m.Error
 }
 
+func (u *Opaque) EncodeXDR(e *xdr.Encoder) error {
+	return e.EncodeRaw(u[:])
+}
+
 func (o *Opaque) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 	copy((*o)[:], u.UnmarshalRaw(32))
 	return u.Error
@@ -188,55 +200,2496 @@ func TestUnmarshalUnexpectedEOF(t *testing.T) {
 		t.Fatal("Unexpected error", err)
 	}
 
-	if err := s.UnmarshalXDR(buf[:len(buf)-1]); err != io.ErrUnexpectedEOF {
+	if err := s.UnmarshalXDR(buf[:len(buf)-1]); !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u := &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalRaw(4)
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalString()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalBytes()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalBool()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalUint8()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalUint16()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:3]}
 	u.UnmarshalUint32()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
 
 	u = &xdr.Unmarshaller{Data: buf[:7]}
 	u.UnmarshalUint64()
-	if err := u.Error; err != io.ErrUnexpectedEOF {
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestUnmarshalSigned(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{
+		0x80, 0x00, 0x00, 0x00,
+		0xff, 0xff, 0xff, 0xff,
+		0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}}
+	if v := u.UnmarshalInt32(); v != math.MinInt32 {
+		t.Errorf("Expected %d, got %d", math.MinInt32, v)
+	}
+	if v := u.UnmarshalInt32(); v != -1 {
+		t.Errorf("Expected -1, got %d", v)
+	}
+	if v := u.UnmarshalInt64(); v != math.MinInt64 {
+		t.Errorf("Expected %d, got %d", int64(math.MinInt64), v)
+	}
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: make([]byte, 3)}
+	u.UnmarshalInt32()
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: make([]byte, 7)}
+	u.UnmarshalInt64()
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestUnmarshalFloat(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{
+		0x3f, 0xc0, 0x00, 0x00,
+		0x7f, 0x80, 0x00, 0x01,
+		0xff, 0xf0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x7f, 0xf8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+	}}
+	if v := u.UnmarshalFloat32(); v != 1.5 {
+		t.Errorf("Expected 1.5, got %v", v)
+	}
+	if v := math.Float32bits(u.UnmarshalFloat32()); v != 0x7f800001 {
+		t.Errorf("Expected NaN bits 0x7f800001, got %#x", v)
+	}
+	if v := u.UnmarshalFloat64(); !math.IsInf(v, -1) {
+		t.Errorf("Expected -Inf, got %v", v)
+	}
+	if v := math.Float64bits(u.UnmarshalFloat64()); v != 0x7ff8000000000001 {
+		t.Errorf("Expected NaN bits 0x7ff8000000000001, got %#x", v)
+	}
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: make([]byte, 3)}
+	u.UnmarshalFloat32()
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: make([]byte, 7)}
+	u.UnmarshalFloat64()
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestMarshalFloatRoundTrip(t *testing.T) {
+	f32 := []uint32{0x80000000, 0x7f800001, 0xff800000, 0x00000001}
+	f64 := []uint64{0x8000000000000000, 0x7ff0000000000001, 0x7ff0000000000000, 0x0000000000000001}
+
+	m := &xdr.Marshaller{Data: make([]byte, 4*len(f32)+8*len(f64))}
+	for _, b := range f32 {
+		m.MarshalFloat32(math.Float32frombits(b))
+	}
+	for _, b := range f64 {
+		m.MarshalFloat64(math.Float64frombits(b))
+	}
+	if err := m.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	for _, b := range f32 {
+		if v := math.Float32bits(u.UnmarshalFloat32()); v != b {
+			t.Errorf("Expected float32 bits %#x, got %#x", b, v)
+		}
+	}
+	for _, b := range f64 {
+		if v := math.Float64bits(u.UnmarshalFloat64()); v != b {
+			t.Errorf("Expected float64 bits %#x, got %#x", b, v)
+		}
+	}
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	m = &xdr.Marshaller{Data: make([]byte, 7)}
+	m.MarshalFloat64(0)
+	if err := m.Error; err != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", err)
+	}
+}
+
+func TestMarshalSigned(t *testing.T) {
+	m := &xdr.Marshaller{Data: make([]byte, 4+4+4+4+8)}
+	m.MarshalInt8(-1)
+	m.MarshalInt16(-2)
+	m.MarshalInt32(math.MinInt32)
+	m.MarshalInt32(-1)
+	m.MarshalInt64(math.MinInt64)
+	if err := m.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	expected := []byte{
+		0x00, 0x00, 0x00, 0xff,
+		0x00, 0x00, 0xff, 0xfe,
+		0x80, 0x00, 0x00, 0x00,
+		0xff, 0xff, 0xff, 0xff,
+		0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	if !bytes.Equal(m.Data, expected) {
+		t.Fatalf("Expected %x, got %x", expected, m.Data)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if v := int8(u.UnmarshalUint8()); v != -1 {
+		t.Errorf("Expected -1, got %d", v)
+	}
+	if v := int16(u.UnmarshalUint16()); v != -2 {
+		t.Errorf("Expected -2, got %d", v)
+	}
+	if v := u.UnmarshalInt32(); v != math.MinInt32 {
+		t.Errorf("Expected %d, got %d", math.MinInt32, v)
+	}
+	if v := u.UnmarshalInt32(); v != -1 {
+		t.Errorf("Expected -1, got %d", v)
+	}
+	if v := u.UnmarshalInt64(); v != math.MinInt64 {
+		t.Errorf("Expected %d, got %d", int64(math.MinInt64), v)
+	}
+}
+
+func TestMarshalBool(t *testing.T) {
+	// Booleans are always encoded as the canonical 0 or 1, which strict
+	// peers require.
+	expected := []byte{
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x00,
+	}
+
+	m := &xdr.Marshaller{Data: make([]byte, 8)}
+	m.MarshalBool(true)
+	m.MarshalBool(false)
+	if err := m.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !bytes.Equal(m.Data, expected) {
+		t.Errorf("Expected %x, got %x", expected, m.Data)
+	}
+
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	e.EncodeBool(true)
+	e.EncodeBool(false)
+	if err := e.Flush(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("Expected %x, got %x", expected, buf.Bytes())
+	}
+}
+
+func TestMarshalDeterministic(t *testing.T) {
+	s := TestStruct{
+		B:   true,
+		BS:  []byte{1},
+		S:   "ab",
+		SS:  []string{"abc", "d"},
+		OSs: []OtherStruct{{F1: 1, F2: "x"}},
+	}
+	expected := s.MustMarshalXDR()
+
+	// A reused buffer full of garbage must not leak into padding.
+	dirty := bytes.Repeat([]byte{0xff}, 2*len(expected))
+	m := xdr.NewMarshaller(dirty[:0])
+	m.Grow(s.XDRSize())
+	if err := s.MarshalXDRInto(m); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !bytes.Equal(m.Bytes(), expected) {
+		t.Errorf("Expected %x, got %x", expected, m.Bytes())
+	}
+
+	for i := 0; i < 2; i++ {
+		m := xdr.AcquireMarshaller()
+		m.Grow(s.XDRSize())
+		if err := s.MarshalXDRInto(m); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if !bytes.Equal(m.Data, expected) {
+			t.Errorf("Expected %x, got %x", expected, m.Data)
+		}
+		for j := range m.Data {
+			m.Data[j] = 0xff
+		}
+		xdr.ReleaseMarshaller(m)
+	}
+}
+
+func TestUnmarshalRemaining(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: make([]byte, 10)}
+	if r := u.Remaining(); r != 10 {
+		t.Errorf("Expected 10 remaining, got %d", r)
+	}
+	u.UnmarshalUint32()
+	if r := u.Remaining(); r != 6 {
+		t.Errorf("Expected 6 remaining, got %d", r)
+	}
+	u.UnmarshalUint64()
+	if r := u.Remaining(); r != 0 {
+		t.Errorf("Expected 0 remaining after error, got %d", r)
+	}
+}
+
+func TestUnmarshalSkip(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 0, 0, 0, 0, 42}}
+	u.Skip(4)
+	if v := u.UnmarshalUint32(); v != 42 {
+		t.Errorf("Expected 42, got %d", v)
+	}
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: make([]byte, 3)}
+	u.Skip(4)
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if len(u.Data) != 3 {
+		t.Errorf("Expected buffer to be left untouched, got %d bytes", len(u.Data))
+	}
+}
+
+func TestUnmarshalReset(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: make([]byte, 3)}
+	u.UnmarshalUint32()
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	u.Reset([]byte{0, 0, 0, 42})
+	if v := u.UnmarshalUint32(); v != 42 {
+		t.Errorf("Expected 42, got %d", v)
+	}
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+}
+
+func TestUnmarshalOffset(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 1, 0, 0, 0, 8, 'x', 0, 0, 0, 0, 0}}
+	u.UnmarshalUint32()
+	if o := u.Offset(); o != 4 {
+		t.Errorf("Expected offset 4, got %d", o)
+	}
+	u.UnmarshalBytes()
+	if o := u.Offset(); o != 4 {
+		t.Errorf("Expected offset 4 after error, got %d", o)
+	}
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if msg := u.Error.Error(); msg != "unexpected EOF at offset 4" {
+		t.Errorf("Unexpected error message %q", msg)
+	}
+
+	u.Reset([]byte{0, 0, 0, 1})
+	if o := u.Offset(); o != 0 {
+		t.Errorf("Expected offset 0 after reset, got %d", o)
+	}
+}
+
+func TestUnmarshalBytesCopy(t *testing.T) {
+	buf := []byte{0, 0, 0, 3, 'a', 'b', 'c', 0}
+	u := &xdr.Unmarshaller{Data: buf}
+	bs := u.UnmarshalBytesCopy()
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	buf[4] = 'x'
+	if string(bs) != "abc" {
+		t.Errorf("Expected copy to be unaffected by buffer changes, got %q", bs)
+	}
+
+	u = &xdr.Unmarshaller{Data: buf}
+	if bs := u.UnmarshalBytesCopyMax(2); bs != nil {
+		t.Errorf("Expected nil, got %q", bs)
+	}
+	if u.Error == nil {
+		t.Fatal("Expected size limit error")
+	}
+}
+
+func TestFixedOpaque(t *testing.T) {
+	m := &xdr.Marshaller{Data: make([]byte, 12)}
+	m.MarshalFixedOpaque([]byte{1, 2, 3, 4, 5})
+	m.MarshalUint32(42)
+	if err := m.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	expected := []byte{1, 2, 3, 4, 5, 0, 0, 0, 0, 0, 0, 42}
+	if !bytes.Equal(m.Data, expected) {
+		t.Fatalf("Expected %x, got %x", expected, m.Data)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if bs := u.UnmarshalFixedOpaque(5); !bytes.Equal(bs, expected[:5]) {
+		t.Errorf("Expected %x, got %x", expected[:5], bs)
+	}
+	if v := u.UnmarshalUint32(); v != 42 {
+		t.Errorf("Expected 42, got %d", v)
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Data[:7]}
+	u.UnmarshalFixedOpaque(5)
+	if err := u.Error; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	m = &xdr.Marshaller{Data: make([]byte, 7)}
+	m.MarshalFixedOpaque([]byte{1, 2, 3, 4, 5})
+	if err := m.Error; err != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", err)
+	}
+}
+
+func TestPadding(t *testing.T) {
+	cases := []struct{ l, p int }{
+		{0, 0}, {1, 3}, {2, 2}, {3, 1}, {4, 0}, {5, 3},
+		{math.MaxInt32, 1}, {math.MaxInt32 - 1, 2}, {math.MaxInt32 - 3, 0},
+		// Size prefixes of 0x80000000 and up are negative on 32 bit builds.
+		{-1, 0}, {-2, 0}, {-3, 0}, {-4, 0}, {math.MinInt32, 0},
+	}
+	for _, tc := range cases {
+		if p := xdr.Padding(tc.l); p != tc.p {
+			t.Errorf("Padding(%d) = %d, expected %d", tc.l, p, tc.p)
+		}
+	}
+}
+
+func TestHostileLengths(t *testing.T) {
+	// Prefixes of 0x80000000 and up are negative on 32 bit builds, where they
+	// fail the size check instead.
+	failed := func(err error) bool {
+		return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, xdr.ErrElementSizeExceeded)
+	}
+	for _, prefix := range [][]byte{
+		{0x7f, 0xff, 0xff, 0xff},
+		{0x7f, 0xff, 0xff, 0xfd},
+		{0x80, 0x00, 0x00, 0x00},
+		{0xff, 0xff, 0xff, 0xff},
+	} {
+		data := append(prefix, 1, 2, 3, 4, 5, 6, 7, 8)
+
+		u := &xdr.Unmarshaller{Data: data}
+		if u.UnmarshalBytes(); !failed(u.Error) {
+			t.Errorf("%x: expected io.ErrUnexpectedEOF, got %v", prefix, u.Error)
+		}
+		u = &xdr.Unmarshaller{Data: data}
+		if u.UnmarshalStringMax(0); !failed(u.Error) {
+			t.Errorf("%x: expected io.ErrUnexpectedEOF, got %v", prefix, u.Error)
+		}
+		u = &xdr.Unmarshaller{Data: data}
+		if u.UnmarshalUint32Slice(0); !failed(u.Error) {
+			t.Errorf("%x: expected io.ErrUnexpectedEOF, got %v", prefix, u.Error)
+		}
+	}
+
+	for _, l := range []int{-1, math.MinInt32, math.MaxInt32, math.MaxInt - 2} {
+		u := &xdr.Unmarshaller{Data: make([]byte, 8)}
+		if u.UnmarshalRaw(l); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+			t.Errorf("UnmarshalRaw(%d): expected io.ErrUnexpectedEOF, got %v", l, u.Error)
+		}
+		u = &xdr.Unmarshaller{Data: make([]byte, 8)}
+		if u.UnmarshalRawPadded(l); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+			t.Errorf("UnmarshalRawPadded(%d): expected io.ErrUnexpectedEOF, got %v", l, u.Error)
+		}
+	}
+}
+
+func TestStrictPadding(t *testing.T) {
+	buf := []byte{0, 0, 0, 1, 'a', 0, 1, 0}
+
+	u := &xdr.Unmarshaller{Data: buf}
+	if s := u.UnmarshalString(); s != "a" || u.Error != nil {
+		t.Fatalf("Expected lenient decode, got %q, %v", s, u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: buf, Strict: true}
+	u.UnmarshalString()
+	if err := u.Error; err != xdr.ErrNonZeroPadding {
+		t.Fatal("Expected xdr.ErrNonZeroPadding, got", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: buf, Strict: true}
+	u.UnmarshalBytes()
+	if err := u.Error; err != xdr.ErrNonZeroPadding {
+		t.Fatal("Expected xdr.ErrNonZeroPadding, got", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: buf[4:], Strict: true}
+	u.UnmarshalFixedOpaque(1)
+	if err := u.Error; err != xdr.ErrNonZeroPadding {
+		t.Fatal("Expected xdr.ErrNonZeroPadding, got", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0, 1, 'a', 0, 0, 0}, Strict: true}
+	if s := u.UnmarshalString(); s != "a" || u.Error != nil {
+		t.Fatalf("Expected strict decode, got %q, %v", s, u.Error)
+	}
+}
+
+func TestStrictBool(t *testing.T) {
+	buf := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 2}
+
+	u := &xdr.Unmarshaller{Data: buf}
+	if u.UnmarshalBool() || !u.UnmarshalBool() || !u.UnmarshalBool() || u.Error != nil {
+		t.Fatal("Unexpected lenient decode, got error", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: buf, Strict: true}
+	if u.UnmarshalBool() || !u.UnmarshalBool() || u.Error != nil {
+		t.Fatal("Unexpected strict decode, got error", u.Error)
+	}
+	u.UnmarshalBool()
+	if err := u.Error; err != xdr.ErrInvalidBool {
+		t.Fatal("Expected xdr.ErrInvalidBool, got", err)
+	}
+}
+
+func TestMarshalWriteTo(t *testing.T) {
+	m := &xdr.Marshaller{Data: make([]byte, 8)}
+	m.MarshalUint32(42)
+
+	var wt io.WriterTo = m
+	var buf bytes.Buffer
+	if n, err := wt.WriteTo(&buf); err != nil || n != 4 {
+		t.Fatal("Unexpected result", n, err)
+	}
+	if expected := []byte{0, 0, 0, 42}; !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("Expected %x, got %x", expected, buf.Bytes())
+	}
+
+	m.MarshalUint64(0)
+	buf.Reset()
+	if _, err := m.WriteTo(&buf); err != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be written, got %x", buf.Bytes())
+	}
+}
+
+func TestUnmarshalBytesInto(t *testing.T) {
+	buf := []byte{0, 0, 0, 3, 'a', 'b', 'c', 0, 0, 0, 0, 42}
+	dst := make([]byte, 4)
+
+	u := &xdr.Unmarshaller{Data: buf}
+	if n := u.UnmarshalBytesInto(dst); n != 3 || string(dst[:n]) != "abc" {
+		t.Errorf("Unexpected result %d, %q", n, dst[:n])
+	}
+	if v := u.UnmarshalUint32(); v != 42 {
+		t.Errorf("Expected 42, got %d", v)
+	}
+	if err := u.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: buf}
+	if n := u.UnmarshalBytesInto(dst[:2]); n != 0 {
+		t.Errorf("Expected nothing copied, got %d", n)
+	}
+	if u.Error == nil {
+		t.Fatal("Expected size limit error")
+	}
+}
+
+func TestMarshalGrow(t *testing.T) {
+	m := xdr.NewMarshallerSize(4)
+	m.MarshalUint32(42)
+	m.Grow(8)
+	m.MarshalUint64(43)
+	if err := m.Error; err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if expected := []byte{0, 0, 0, 42, 0, 0, 0, 0, 0, 0, 0, 43}; !bytes.Equal(m.Data, expected) {
+		t.Errorf("Expected %x, got %x", expected, m.Data)
+	}
+
+	m = &xdr.Marshaller{Data: make([]byte, 0, 16)}
+	m.Grow(4)
+	if len(m.Data) != 4 || cap(m.Data) != 16 {
+		t.Errorf("Expected reslice within capacity, got len %d cap %d", len(m.Data), cap(m.Data))
+	}
+}
+
+func TestSizes(t *testing.T) {
+	for l, expected := range []int{4, 8, 8, 8, 8, 12} {
+		if s := xdr.BytesSize(l); s != expected {
+			t.Errorf("BytesSize(%d) = %d, expected %d", l, s, expected)
+		}
+	}
+	if s := xdr.StringSize("hello"); s != 12 {
+		t.Errorf("StringSize(\"hello\") = %d, expected 12", s)
+	}
+	if s := xdr.Uint32Size(); s != 4 {
+		t.Errorf("Uint32Size() = %d, expected 4", s)
+	}
+	if s := xdr.Uint64Size(); s != 8 {
+		t.Errorf("Uint64Size() = %d, expected 8", s)
+	}
+}
+
+type StringsStruct struct {
+	Tags []string
+}
+
+func TestUnmarshalHostileSliceCount(t *testing.T) {
+	var s StringsStruct
+	if err := s.UnmarshalXDR([]byte{0x7f, 0xff, 0xff, 0xff, 0, 0, 0, 0}); !errors.Is(err, io.ErrUnexpectedEOF) {
 		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
 	}
+	if s.Tags != nil {
+		t.Errorf("Expected no allocation, got %d elements", len(s.Tags))
+	}
+
+	s.Tags = []string{"a", "bc"}
+	var t1 StringsStruct
+	if err := t1.UnmarshalXDR(s.MustMarshalXDR()); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(t1.Tags) != 2 || t1.Tags[0] != "a" || t1.Tags[1] != "bc" {
+		t.Errorf("Unexpected result %q", t1.Tags)
+	}
+}
+
+type Batch struct {
+	Items []Item
+}
+
+type Item struct {
+	Tags []string // max:2
+}
+
+func TestUnmarshalNestedSliceError(t *testing.T) {
+	b := Batch{Items: []Item{{Tags: []string{"a"}}, {Tags: []string{"b", "c"}}}}
+	var b1 Batch
+	if err := b1.UnmarshalXDR(b.MustMarshalXDR()); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(b1.Items) != 2 || len(b1.Items[1].Tags) != 2 || b1.Items[1].Tags[1] != "c" {
+		t.Errorf("Unexpected result %v", b1.Items)
+	}
+
+	bs := []byte{
+		0, 0, 0, 1, // one item
+		0, 0, 0, 3, // three tags
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	err := b1.UnmarshalXDR(bs)
+	if err == nil || !strings.Contains(err.Error(), "Tags exceeds size limit") {
+		t.Fatal("Expected Tags size limit error, got", err)
+	}
+}
+
+// Status is an XDR enum.
+//
+//xdr:enum
+type Status int32
+
+const (
+	StatusOK Status = iota + 1
+	StatusFailed
+	StatusUnknown
+)
+
+type EnumStruct struct {
+	S  Status
+	Ss []Status // max:8
+}
+
+func TestEnum(t *testing.T) {
+	e0 := EnumStruct{S: StatusFailed, Ss: []Status{StatusOK, StatusUnknown}}
+	var e1 EnumStruct
+	if err := e1.UnmarshalXDR(e0.MustMarshalXDR()); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if e1.S != e0.S || len(e1.Ss) != 2 || e1.Ss[0] != StatusOK || e1.Ss[1] != StatusUnknown {
+		t.Errorf("Unexpected result %v", e1)
+	}
+
+	for _, v := range []Status{0, 4, -1} {
+		e0 = EnumStruct{S: v}
+		if err := e1.UnmarshalXDR(e0.MustMarshalXDR()); err == nil {
+			t.Errorf("Expected error for invalid value %d", v)
+		}
+	}
+}
+
+func TestUnmarshalEnum(t *testing.T) {
+	data := []byte{0, 0, 0, 2, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 3}
+	u := &xdr.Unmarshaller{Data: data}
+	if v := u.UnmarshalEnum(1, 2); v != 2 {
+		t.Error("Expected 2, got", v)
+	}
+	if v := u.UnmarshalEnumSet(map[int32]bool{-1: true}); v != -1 {
+		t.Error("Expected -1, got", v)
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+	if v := u.UnmarshalEnum(1, 2); v != 0 || u.Error == nil {
+		t.Errorf("Expected error for invalid value, got %d, %v", v, u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: data[8:]}
+	if v := u.UnmarshalEnumSet(map[int32]bool{3: false}); v != 0 || u.Error == nil {
+		t.Errorf("Expected error for invalid value, got %d, %v", v, u.Error)
+	}
+}
+
+func TestEnumString(t *testing.T) {
+	for v, s := range map[Status]string{
+		StatusOK:     "StatusOK",
+		StatusFailed: "StatusFailed",
+		0:            "Status(0)",
+		-1:           "Status(-1)",
+		42:           "Status(42)",
+	} {
+		if v.String() != s {
+			t.Errorf("%d: %q != %q", int32(v), v.String(), s)
+		}
+	}
+}
+
+// Result is an XDR union holding the outcome of an operation.
+//
+//xdr:union
+type Result struct {
+	Code  Status
+	Value interface{} // arms: StatusOK=OtherStruct, StatusFailed=Failure, StatusUnknown=void
+}
+
+type Failure struct {
+	Reason string // max:64
+}
+
+func TestUnion(t *testing.T) {
+	for _, r0 := range []Result{
+		{Code: StatusOK, Value: &OtherStruct{F1: 42, F2: "ok"}},
+		{Code: StatusFailed, Value: &Failure{Reason: "failed"}},
+		{Code: StatusUnknown},
+	} {
+		bs, err := r0.MarshalXDR()
+		if err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if len(bs) != r0.XDRSize() {
+			t.Errorf("Expected %d bytes, got %d", r0.XDRSize(), len(bs))
+		}
+
+		var r1 Result
+		if err := r1.UnmarshalXDR(bs); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if !reflect.DeepEqual(r0, r1) {
+			t.Errorf("Expected %+v, got %+v", r0, r1)
+		}
+	}
+
+	if _, err := (Result{Code: StatusOK, Value: &Failure{}}).MarshalXDR(); err == nil {
+		t.Error("Expected error for mismatched arm")
+	}
+	if _, err := (Result{Code: 0}).MarshalXDR(); err == nil {
+		t.Error("Expected error for unknown discriminant")
+	}
+
+	var r Result
+	if err := r.UnmarshalXDR([]byte{0, 0, 0, 0}); err == nil {
+		t.Error("Expected error for unknown discriminant")
+	}
+}
+
+type OptionalStruct struct {
+	N *uint32
+	S *string // max:16
+	O *OtherStruct
+	E *Status
+}
+
+func TestOptional(t *testing.T) {
+	n := uint32(7)
+	s := "present"
+	e := StatusFailed
+	for _, o0 := range []OptionalStruct{
+		{},
+		{N: &n, S: &s, O: &OtherStruct{F1: 1, F2: "x"}, E: &e},
+		{S: &s},
+	} {
+		bs, err := o0.MarshalXDR()
+		if err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if len(bs) != o0.XDRSize() {
+			t.Errorf("Expected %d bytes, got %d", o0.XDRSize(), len(bs))
+		}
+
+		// Decode into a populated value to check that absent fields are cleared.
+		o1 := OptionalStruct{N: new(uint32), S: new(string), O: &OtherStruct{}, E: new(Status)}
+		if err := o1.UnmarshalXDR(bs); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if !reflect.DeepEqual(o0, o1) {
+			t.Errorf("Expected %+v, got %+v", o0, o1)
+		}
+	}
+
+	bs := (OptionalStruct{}).MustMarshalXDR()
+	if !bytes.Equal(bs, make([]byte, 16)) {
+		t.Errorf("Expected four absent flags, got %x", bs)
+	}
+}
+
+type TaggedStruct struct {
+	Name    string   `xdr:"max=8"`
+	Blob    []byte   `xdr:"max=16"`
+	Tags    []string `xdr:"max=2"`
+	Scratch uint32   `xdr:"-"`
+	Note    []byte   // xdr:max=4
+}
+
+func TestTaggedStruct(t *testing.T) {
+	t0 := TaggedStruct{Name: "name", Blob: []byte{1, 2, 3}, Tags: []string{"a"}, Scratch: 42, Note: []byte{4}}
+	bs, err := t0.MarshalXDR()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// The generated and reflection based code must agree on the tags.
+	rbs, err := xdr.Marshal(struct {
+		Name    string   `xdr:"max=8"`
+		Blob    []byte   `xdr:"max=16"`
+		Tags    []string `xdr:"max=2"`
+		Scratch uint32   `xdr:"-"`
+		Note    []byte   `xdr:"max=4"`
+	}(t0))
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !bytes.Equal(bs, rbs) {
+		t.Errorf("Generated %x != reflected %x", bs, rbs)
+	}
+
+	for _, t1 := range []TaggedStruct{
+		{Name: "too long name"},
+		{Blob: make([]byte, 17)},
+		{Tags: []string{"a", "b", "c"}},
+		{Note: make([]byte, 5)},
+	} {
+		if _, err := t1.MarshalXDR(); err == nil {
+			t.Errorf("Expected error marshalling %+v", t1)
+		}
+	}
+
+	// An oversized length prefix is rejected before allocating
+	var t2 TaggedStruct
+	if err := t2.UnmarshalXDR([]byte{0, 0, 0, 0, 0x7f, 0xff, 0xff, 0xff}); err == nil {
+		t.Error("Expected error for oversized Blob")
+	}
+	if err := t2.UnmarshalXDR([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5}); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded for oversized Note, got", err)
+	}
+}
+
+type MapStruct struct {
+	Labels   map[string]string // max:4, 8
+	Counts   map[string]int64
+	Others   map[string]OtherStruct // max:2
+	Statuses map[string]Status
+}
+
+func TestMapStruct(t *testing.T) {
+	m0 := MapStruct{
+		Labels:   map[string]string{"b": "two", "a": "one", "c": "three"},
+		Counts:   map[string]int64{"x": -1, "y": 1 << 40},
+		Others:   map[string]OtherStruct{"o": {F1: 1, F2: "x"}},
+		Statuses: map[string]Status{"s": StatusFailed},
+	}
+	bs, err := m0.MarshalXDR()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(bs) != m0.XDRSize() {
+		t.Errorf("Expected %d bytes, got %d", m0.XDRSize(), len(bs))
+	}
+
+	// Pairs are sorted by key, whatever the iteration order.
+	for i := 0; i < 10; i++ {
+		if bs1 := m0.MustMarshalXDR(); !bytes.Equal(bs, bs1) {
+			t.Fatalf("Encoding differs between runs: %x != %x", bs, bs1)
+		}
+	}
+	expected := []byte{
+		0, 0, 0, 3,
+		0, 0, 0, 1, 'a', 0, 0, 0, 0, 0, 0, 3, 'o', 'n', 'e', 0,
+		0, 0, 0, 1, 'b', 0, 0, 0, 0, 0, 0, 3, 't', 'w', 'o', 0,
+		0, 0, 0, 1, 'c', 0, 0, 0, 0, 0, 0, 5, 't', 'h', 'r', 'e', 'e', 0, 0, 0,
+	}
+	if !bytes.HasPrefix(bs, expected) {
+		t.Errorf("Expected prefix %x, got %x", expected, bs)
+	}
+
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	if err := m0.EncodeXDR(e); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !bytes.Equal(buf.Bytes(), bs) {
+		t.Errorf("Encoder wrote %x, Marshaller %x", buf.Bytes(), bs)
+	}
+
+	var m1 MapStruct
+	if err := m1.UnmarshalXDR(bs); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !reflect.DeepEqual(m0, m1) || !m0.Equal(m1) {
+		t.Errorf("Expected %+v, got %+v", m0, m1)
+	}
+
+	for _, m2 := range []MapStruct{
+		{Labels: map[string]string{"a": "", "b": "", "c": "", "d": "", "e": ""}},
+		{Others: map[string]OtherStruct{"a": {}, "b": {}, "c": {}}},
+	} {
+		if _, err := m2.MarshalXDR(); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+			t.Error("Expected ErrElementSizeExceeded, got", err)
+		}
+	}
+
+	// Counts and values over their max are rejected when decoding.
+	if err := m1.UnmarshalXDR([]byte{0, 0, 0, 5}); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", err)
+	}
+	if err := m1.UnmarshalXDR([]byte{0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 9}); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", err)
+	}
+	if err := m1.UnmarshalXDR([]byte{0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 0}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	// The max of a map also limits the length of its keys.
+	if _, err := (MapStruct{Labels: map[string]string{"abcde": ""}}).MarshalXDR(); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", err)
+	}
+	if err := m1.UnmarshalXDR([]byte{0, 0, 0, 1, 0, 0, 0, 5, 'a', 'b', 'c', 'd', 'e', 0, 0, 0}); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", err)
+	}
+
+	// Strict mode rejects keys out of order or repeated.
+	for _, keys := range []string{"ba", "aa"} {
+		bs := []byte{0, 0, 0, 0, 0, 0, 0, 2}
+		for _, k := range []byte(keys) {
+			bs = append(bs, 0, 0, 0, 1, k, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+		}
+		bs = append(bs, 0, 0, 0, 0, 0, 0, 0, 0)
+		if err := m1.UnmarshalXDR(bs); err != nil {
+			t.Errorf("Unexpected error for keys %q: %v", keys, err)
+		}
+		if err := m1.UnmarshalXDRFrom(&xdr.Unmarshaller{Data: bs, Strict: true}); err != xdr.ErrMapKeyOrder {
+			t.Errorf("Expected ErrMapKeyOrder for keys %q, got %v", keys, err)
+		}
+	}
+	if err := m0.UnmarshalXDRFrom(&xdr.Unmarshaller{Data: m0.MustMarshalXDR(), Strict: true}); err != nil {
+		t.Error("Unexpected error", err)
+	}
+}
+
+func TestSliceHelpers(t *testing.T) {
+	s0 := []string{"a", "bc", "def"}
+	m := xdr.NewMarshallerSize(4 + xdr.SizeOfSlice(s0))
+	xdr.MarshalSlice(m, s0, (*xdr.Marshaller).MarshalString)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	s1 := xdr.UnmarshalSlice(u, (*xdr.Unmarshaller).UnmarshalString, 3)
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+	if !reflect.DeepEqual(s0, s1) {
+		t.Errorf("Expected %v, got %v", s0, s1)
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Data}
+	if s := xdr.UnmarshalSlice(u, (*xdr.Unmarshaller).UnmarshalString, 2); s != nil || u.Error == nil {
+		t.Error("Expected error for count above max")
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0x7f, 0xff, 0xff, 0xff}}
+	xdr.UnmarshalSlice(u, (*xdr.Unmarshaller).UnmarshalUint32, 0)
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestSliceXDRSize(t *testing.T) {
+	if l := xdr.SliceXDRSize([]OtherStruct(nil)); l != 4 {
+		t.Errorf("Expected 4 for an empty slice, got %d", l)
+	}
+
+	s := []OtherStruct{{F1: 1, F2: "a"}, {F2: "abcde"}, {}}
+	m := xdr.NewMarshallerSize(xdr.SliceXDRSize(s))
+	xdr.MarshalSlice(m, s, func(m *xdr.Marshaller, o OtherStruct) { o.MarshalXDRInto(m) })
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+	if l := xdr.SliceXDRSize(s); l != len(m.Data) || l != 4+12+16+8 {
+		t.Errorf("Expected %d bytes, got %d", len(m.Data), l)
+	}
+}
+
+func TestUint32Slice(t *testing.T) {
+	v32 := []uint32{1, 2, 0xffffffff}
+	v64 := []uint64{3, 0xffffffffffffffff}
+	m := xdr.NewMarshallerSize(4 + 4*len(v32) + 4 + 8*len(v64))
+	m.MarshalUint32Slice(v32)
+	m.MarshalUint64Slice(v64)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if v := u.UnmarshalUint32Slice(3); !reflect.DeepEqual(v, v32) {
+		t.Errorf("Expected %v, got %v", v32, v)
+	}
+	if v := u.UnmarshalUint64Slice(0); !reflect.DeepEqual(v, v64) {
+		t.Errorf("Expected %v, got %v", v64, v)
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Data}
+	if v := u.UnmarshalUint32Slice(2); v != nil || u.Error == nil {
+		t.Error("Expected error for count above max")
+	}
+
+	// The count claims more elements than the data holds
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 1}}
+	u.UnmarshalUint64Slice(0)
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+
+	m = xdr.NewMarshallerSize(8)
+	m.MarshalUint32Slice(v32)
+	if m.Error != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", m.Error)
+	}
+}
+
+func TestBoolSlice(t *testing.T) {
+	vs := []bool{true, false, true}
+	m := xdr.NewMarshallerSize(4 + 4*len(vs))
+	m.MarshalBoolSlice(vs)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+	expected := []byte{0, 0, 0, 3, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}
+	if !bytes.Equal(m.Data, expected) {
+		t.Errorf("Expected %x, got %x", expected, m.Data)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if v := u.UnmarshalBoolSlice(3); !reflect.DeepEqual(v, vs) {
+		t.Errorf("Expected %v, got %v", vs, v)
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Data}
+	if v := u.UnmarshalBoolSlice(2); v != nil || !errors.Is(u.Error, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2}, Strict: true}
+	if v := u.UnmarshalBoolSlice(0); v != nil || u.Error != xdr.ErrInvalidBool {
+		t.Error("Expected ErrInvalidBool, got", u.Error)
+	}
+
+	m = xdr.NewMarshallerSize(8)
+	m.MarshalBoolSlice(vs)
+	if m.Error != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", m.Error)
+	}
+}
+
+func TestPeekUint32(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 7, 0, 0}}
+	if v := u.PeekUint32(); v != 7 {
+		t.Errorf("Expected 7, got %d", v)
+	}
+	if u.Offset() != 0 || len(u.Data) != 6 {
+		t.Errorf("PeekUint32 consumed data; offset %d, %d bytes left", u.Offset(), len(u.Data))
+	}
+	if v := u.UnmarshalUint32(); v != 7 {
+		t.Errorf("Expected 7, got %d", v)
+	}
+
+	u.PeekUint32()
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestBinaryMarshaler(t *testing.T) {
+	var _ encoding.BinaryMarshaler = TestStruct{}
+	var _ encoding.BinaryUnmarshaler = &TestStruct{}
+	var _ encoding.BinaryMarshaler = Status(0)
+	var _ encoding.BinaryUnmarshaler = new(Status)
+
+	o0 := OtherStruct{F1: 1, F2: "binary"}
+	bs, err := o0.MarshalBinary()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !bytes.Equal(bs, o0.MustMarshalXDR()) {
+		t.Errorf("MarshalBinary %x != MarshalXDR %x", bs, o0.MustMarshalXDR())
+	}
+
+	var o1 OtherStruct
+	if err := o1.UnmarshalBinary(bs); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if o0 != o1 {
+		t.Errorf("Expected %+v, got %+v", o0, o1)
+	}
+
+	if _, err := (TaggedStruct{Name: "too long name"}).MarshalBinary(); err == nil {
+		t.Error("Expected error for oversized Name")
+	}
+	if err := o1.UnmarshalBinary(bs[:6]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestElementSizeError(t *testing.T) {
+	var t0 TaggedStruct
+	err := t0.UnmarshalXDR([]byte{0, 0, 0, 9, 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 0, 0, 0})
+	if !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected xdr.ErrElementSizeExceeded, got", err)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Size error must not match io.ErrUnexpectedEOF")
+	}
+
+	var se *xdr.ElementSizeError
+	if !errors.As(err, &se) {
+		t.Fatal("Expected *xdr.ElementSizeError, got", err)
+	}
+	if se.Field != "Name" || se.Size != 9 || se.Max != 8 {
+		t.Errorf("Expected Name size 9 > 8, got %s size %d > %d", se.Field, se.Size, se.Max)
+	}
+
+	// Labels elements are limited to 8 bytes.
+	var n NamedStruct
+	bs := NamedStruct{Labels: []Label{"abcdefghi"}}.MustMarshalXDR()
+	if err := n.UnmarshalXDR(bs); !errors.As(err, &se) || se.Field != "Labels" {
+		t.Fatal("Expected a size error for Labels, got", err)
+	}
+
+	err = t0.UnmarshalXDR([]byte{0, 0, 0, 8, 'a'})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestCheckLength(t *testing.T) {
+	// Lengths with the high bit set are rejected, with the same error, whether
+	// int is 32 or 64 bits wide.
+	bs := []byte{0x80, 0, 0, 0, 0, 0, 0, 0}
+	check := func(what string, err error) {
+		t.Helper()
+		var se *xdr.ElementSizeError
+		if !errors.As(err, &se) {
+			t.Fatalf("%s: expected *xdr.ElementSizeError, got %v", what, err)
+		}
+		if se.Size != 1<<31 || se.Max != math.MaxInt32 {
+			t.Errorf("%s: expected size 2147483648 > 2147483647, got %d > %d", what, se.Size, se.Max)
+		}
+	}
+
+	u := &xdr.Unmarshaller{Data: bs}
+	u.UnmarshalBytes()
+	check("UnmarshalBytes", u.Error)
+
+	u = &xdr.Unmarshaller{Data: bs}
+	u.UnmarshalUint32Slice(0)
+	check("UnmarshalUint32Slice", u.Error)
+
+	u = &xdr.Unmarshaller{Data: bs}
+	if l := u.UnmarshalLength("length", 0); l != 0 {
+		t.Error("Expected zero length on error, got", l)
+	}
+	check("UnmarshalLength", u.Error)
+
+	var s StringsStruct
+	check("UnmarshalXDR", s.UnmarshalXDR(bs))
+
+	var v struct{ S []uint32 }
+	check("Unmarshal", xdr.Unmarshal(bs, &v))
+
+	_, err := xdr.NewDecoder(bytes.NewReader(bs)).DecodeBytes()
+	check("DecodeBytes", err)
+
+	_, err = xdr.ReadRecord(bytes.NewReader(bs))
+	check("ReadRecord", err)
+
+	if l, err := xdr.CheckLength("length", math.MaxInt32, 0); l != math.MaxInt32 || err != nil {
+		t.Error("Expected math.MaxInt32 to be accepted, got", l, err)
+	}
+	if _, err := xdr.CheckLength("length", 9, 8); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected xdr.ErrElementSizeExceeded, got", err)
+	}
+}
+
+func TestMarshallerPool(t *testing.T) {
+	o := OtherStruct{F1: 1, F2: "pooled"}
+	for i := 0; i < 3; i++ {
+		m := xdr.AcquireMarshaller()
+		if len(m.Data) != 0 || m.Error != nil {
+			t.Fatalf("Acquired a dirty Marshaller: %d bytes, error %v", len(m.Data), m.Error)
+		}
+
+		m.Grow(o.XDRSize())
+		if err := o.MarshalXDRInto(m); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if !bytes.Equal(m.Data, o.MustMarshalXDR()) {
+			t.Errorf("Expected %x, got %x", o.MustMarshalXDR(), m.Data)
+		}
+
+		// Leave an error behind to check that it is cleared
+		m.MarshalUint32(0)
+		xdr.ReleaseMarshaller(m)
+	}
+}
+
+func TestMarshalValidateUTF8(t *testing.T) {
+	invalid := "a\xffb"
+
+	m := xdr.NewMarshallerSize(8)
+	m.MarshalString(invalid)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+
+	m = xdr.NewMarshallerSize(16)
+	m.ValidateUTF8 = true
+	m.MarshalString("héllo")
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+	m.MarshalString(invalid)
+	if m.Error != xdr.ErrInvalidUTF8 {
+		t.Fatal("Expected xdr.ErrInvalidUTF8, got", m.Error)
+	}
+}
+
+func TestUnmarshalStringOK(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 2, 'h', 'i', 0, 0, 0, 0, 0, 0, 0, 0, 0, 8}}
+	if s, ok := u.UnmarshalStringOK(); s != "hi" || !ok {
+		t.Errorf("Expected \"hi\", true; got %q, %v", s, ok)
+	}
+	if s, ok := u.UnmarshalStringOK(); s != "" || ok {
+		t.Errorf("Expected \"\", false; got %q, %v", s, ok)
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+	if s, ok := u.UnmarshalStringOK(); s != "" || ok {
+		t.Errorf("Expected \"\", false; got %q, %v", s, ok)
+	}
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestTryUnmarshal(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 7, 0, 0, 0, 2, 'h', 'i', 0, 0, 0, 0}}
+	if v, err := u.TryUnmarshalUint32(); v != 7 || err != nil {
+		t.Errorf("Expected 7, nil; got %d, %v", v, err)
+	}
+	if s, err := u.TryUnmarshalStringMax(1); s != "" || !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Errorf("Expected \"\", ErrElementSizeExceeded; got %q, %v", s, err)
+	}
+
+	// The error latches, and is returned by later calls.
+	if v, err := u.TryUnmarshalUint32(); v != 0 || !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Errorf("Expected 0, ErrElementSizeExceeded; got %d, %v", v, err)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0}}
+	if v, err := u.TryUnmarshalInt64(); v != 0 || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected 0, io.ErrUnexpectedEOF; got %d, %v", v, err)
+	}
+}
+
+func TestUnmarshalBytesN(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 5, 1, 2, 3, 4, 5, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 9}}
+	if v, n := u.UnmarshalBytesN(); len(v) != 5 || n != 12 {
+		t.Errorf("Expected 5 bytes and 12 consumed, got %d and %d", len(v), n)
+	}
+	if v, n := u.UnmarshalBytesN(); v != nil || n != 4 {
+		t.Errorf("Expected nil and 4 consumed, got %v and %d", v, n)
+	}
+	if v, n := u.UnmarshalBytesN(); v != nil || n != 0 {
+		t.Errorf("Expected nil and 0 consumed, got %v and %d", v, n)
+	}
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestUnmarshalRemainingBytes(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 1, 2, 3, 4}}
+	u.UnmarshalUint32()
+	if v := u.UnmarshalRemaining(); !bytes.Equal(v, []byte{2, 3, 4}) {
+		t.Errorf("Expected 020304, got %x", v)
+	}
+	if u.Remaining() != 0 || u.Offset() != 7 {
+		t.Errorf("Expected empty buffer at offset 7, got %d bytes at offset %d", u.Remaining(), u.Offset())
+	}
+
+	u.UnmarshalUint32()
+	if v := u.UnmarshalRemaining(); v != nil {
+		t.Errorf("Expected nil after error, got %x", v)
+	}
+}
+
+type FloatStruct struct {
+	F32 float32
+	F64 float64
+	Fs  []float64
+}
+
+func TestEqual(t *testing.T) {
+	s0 := TestStruct{
+		BS:  []byte{1, 2},
+		SS:  []string{"a", "b"},
+		OSs: []OtherStruct{{F1: 1, F2: "x"}},
+	}
+	s1 := s0
+	s1.OSs = []OtherStruct{{F1: 1, F2: "x"}}
+	if !s0.Equal(s1) {
+		t.Error("Expected equal structs")
+	}
+	s1.OSs[0].F2 = "y"
+	if s0.Equal(s1) {
+		t.Error("Expected nested slice element to differ")
+	}
+
+	// Nil and empty slices encode alike, so they are equal.
+	if !(TestStruct{BS: []byte{}, SS: []string{}}).Equal(TestStruct{}) {
+		t.Error("Expected nil and empty slices to be equal")
+	}
+
+	n := uint32(1)
+	if (OptionalStruct{N: &n}).Equal(OptionalStruct{}) {
+		t.Error("Expected present and absent optional data to differ")
+	}
+	m := uint32(1)
+	if !(OptionalStruct{N: &n}).Equal(OptionalStruct{N: &m}) {
+		t.Error("Expected optional data to be compared by value")
+	}
+
+	r0 := Result{Code: StatusOK, Value: &OtherStruct{F1: 1}}
+	if !r0.Equal(Result{Code: StatusOK, Value: &OtherStruct{F1: 1}}) {
+		t.Error("Expected equal unions")
+	}
+	if r0.Equal(Result{Code: StatusOK, Value: &OtherStruct{F1: 2}}) {
+		t.Error("Expected union arm values to differ")
+	}
+	if r0.Equal(Result{Code: StatusFailed, Value: &Failure{}}) {
+		t.Error("Expected union discriminants to differ")
+	}
+
+	// Floats are compared by their bits.
+	nan := math.NaN()
+	if !(FloatStruct{F64: nan, Fs: []float64{nan}}).Equal(FloatStruct{F64: nan, Fs: []float64{nan}}) {
+		t.Error("Expected NaN to equal itself")
+	}
+	if (FloatStruct{F32: float32(math.Copysign(0, -1))}).Equal(FloatStruct{}) {
+		t.Error("Expected 0 and -0 to differ")
+	}
+}
+
+// Chain is a recursive type, a linked list of optional values.
+type Chain struct {
+	V    uint32
+	Next *Chain
+}
+
+// chainData returns the encoding of a Chain of n links.
+func chainData(n int) []byte {
+	var bs []byte
+	for i := 0; i < n; i++ {
+		bs = append(bs, 0, 0, 0, byte(i), 0, 0, 0, 1)
+	}
+	return append(bs[:len(bs)-4], 0, 0, 0, 0)
+}
+
+func TestAllocBudget(t *testing.T) {
+	tags := make([]string, 100)
+	for i := range tags {
+		tags[i] = "0123456789"
+	}
+	bs := StringsStruct{Tags: tags}.MustMarshalXDR()
+	size := len(tags)*int(unsafe.Sizeof(tags[0])) + len(tags)*10
+
+	var s StringsStruct
+	u := &xdr.Unmarshaller{Data: bs, AllocBudget: size}
+	if err := s.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// The budget covers the whole message, not each field.
+	s = StringsStruct{}
+	u.Reset(append(bs, bs...))
+	u.AllocBudget = size + 9
+	s.UnmarshalXDRFrom(u)
+	s = StringsStruct{}
+	if err := s.UnmarshalXDRFrom(u); err != xdr.ErrAllocBudget {
+		t.Fatal("Expected ErrAllocBudget, got", err)
+	}
+
+	// Nested messages charge the same budget.
+	e0 := Envelope{Record: RecordV1{Name: "0123456789"}}
+	u = &xdr.Unmarshaller{Data: append(e0.MustMarshalXDR(), e0.MustMarshalXDR()...), AllocBudget: 15}
+	var e1 Envelope
+	if err := e1.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if err := e1.UnmarshalXDRFrom(u); err != xdr.ErrAllocBudget {
+		t.Fatal("Expected ErrAllocBudget, got", err)
+	}
+}
+
+func TestStringInterner(t *testing.T) {
+	bs := StringsStruct{Tags: []string{"alpha", "beta", "alpha", "beta"}}.MustMarshalXDR()
+
+	si := xdr.NewStringInterner(0)
+	s := StringsStruct{Tags: make([]string, 0, 4)}
+	u := &xdr.Unmarshaller{Data: bs, Interner: si}
+	if err := s.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if si.Len() != 2 {
+		t.Error("Expected 2 interned strings, got", si.Len())
+	}
+	if unsafe.StringData(s.Tags[0]) != unsafe.StringData(s.Tags[2]) {
+		t.Error("Expected repeated values to share their data")
+	}
+
+	// Once the values have been seen, decoding them allocates nothing.
+	if n := testing.AllocsPerRun(100, func() {
+		u.Reset(bs)
+		s.UnmarshalXDRFrom(u)
+	}); n != 0 {
+		t.Errorf("Expected no allocations, got %v", n)
+	}
+
+	// Interned strings are free, others still charge the budget.
+	u = &xdr.Unmarshaller{Data: bs, Interner: si, AllocBudget: 1}
+	if err := s.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	u = &xdr.Unmarshaller{Data: bs, AllocBudget: 1}
+	if err := s.UnmarshalXDRFrom(u); err != xdr.ErrAllocBudget {
+		t.Fatal("Expected ErrAllocBudget, got", err)
+	}
+
+	// A full interner still decodes new values, without keeping them.
+	si = xdr.NewStringInterner(1)
+	u = &xdr.Unmarshaller{Data: bs, Interner: si}
+	if err := s.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if si.Len() != 1 || !reflect.DeepEqual(s.Tags, []string{"alpha", "beta", "alpha", "beta"}) {
+		t.Errorf("Unexpected %d interned strings decoding %q", si.Len(), s.Tags)
+	}
+}
+
+func TestZeroOnError(t *testing.T) {
+	secret := []byte{0, 0, 0, 4, 's', 'e', 'c', 'r', 0, 0, 0, 9, 'x'}
+	bs := append([]byte(nil), secret...)
+	u := &xdr.Unmarshaller{Data: bs, ZeroOnError: true}
+	if v := u.UnmarshalBytesCopy(); string(v) != "secr" {
+		t.Fatalf("Expected secr, got %q", v)
+	}
+	if u.UnmarshalBytes(); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+	// The data consumed is left alone, what follows the failure is zeroed.
+	if !bytes.Equal(bs[:8], secret[:8]) || !bytes.Equal(bs[8:], make([]byte, 5)) {
+		t.Errorf("Unexpected buffer %x", bs)
+	}
+
+	// Without it, nothing is touched.
+	bs = append(bs[:0], secret...)
+	u = &xdr.Unmarshaller{Data: bs}
+	u.UnmarshalBytes()
+	u.UnmarshalBytes()
+	if !bytes.Equal(bs, secret) {
+		t.Errorf("Unexpected buffer %x", bs)
+	}
+
+	// Errors found by the generated code zero the data as well, and only
+	// the first error counts.
+	bs = []byte{0, 0, 0, 65, 1, 2, 3, 4}
+	u = &xdr.Unmarshaller{Data: bs, ZeroOnError: true}
+	var f Failure
+	if err := f.UnmarshalXDRFrom(u); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected ErrElementSizeExceeded, got", err)
+	}
+	if !bytes.Equal(bs, make([]byte, 8)) {
+		t.Errorf("Unexpected buffer %x", bs)
+	}
+	if err := u.Fail(xdr.ErrInvalidBool); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected the first error to be kept, got", err)
+	}
+}
+
+func TestHints(t *testing.T) {
+	small := StringsStruct{Tags: []string{"a"}}.MustMarshalXDR()
+	large := StringsStruct{Tags: []string{"a", "b", "c", "d"}}.MustMarshalXDR()
+
+	var s StringsStruct
+	if err := s.UnmarshalXDRInto(small, xdr.Hints{"StringsStruct.Tags": 4}); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(s.Tags) != 1 || cap(s.Tags) != 4 {
+		t.Errorf("Expected len 1 cap 4, got len %d cap %d", len(s.Tags), cap(s.Tags))
+	}
+
+	// A message up to the hinted size then reuses the slice.
+	backing := &s.Tags[0]
+	if err := s.UnmarshalXDR(large); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(s.Tags) != 4 || &s.Tags[0] != backing {
+		t.Error("Expected the hinted backing array to be reused")
+	}
+
+	// Larger counts than hinted are allocated as they are, and the hinted
+	// capacity counts against the budget.
+	s = StringsStruct{}
+	if err := s.UnmarshalXDRInto(large, xdr.Hints{"StringsStruct.Tags": 2}); err != nil || cap(s.Tags) != 4 {
+		t.Fatalf("Unexpected cap %d (%v)", cap(s.Tags), err)
+	}
+	s = StringsStruct{}
+	u := &xdr.Unmarshaller{Data: small, Hints: xdr.Hints{"StringsStruct.Tags": 1000}, AllocBudget: 1000}
+	if err := s.UnmarshalXDRFrom(u); err != xdr.ErrAllocBudget {
+		t.Fatal("Expected ErrAllocBudget, got", err)
+	}
+
+	// Hints reach nested structs.
+	var b Batch
+	bs := Batch{Items: []Item{{Tags: []string{"x"}}}}.MustMarshalXDR()
+	if err := b.UnmarshalXDRInto(bs, xdr.Hints{"Batch.Items": 3, "Item.Tags": 2}); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if cap(b.Items) != 3 || cap(b.Items[0].Tags) != 2 {
+		t.Errorf("Expected caps 3 and 2, got %d and %d", cap(b.Items), cap(b.Items[0].Tags))
+	}
+}
+
+func TestResume(t *testing.T) {
+	var stream []byte
+	var want []StringsStruct
+	for i := 0; i < 5; i++ {
+		s := StringsStruct{Tags: []string{strings.Repeat("x", i), "tag"}}
+		stream = append(stream, s.MustMarshalXDR()...)
+		want = append(want, s)
+	}
+
+	// Feed the stream in chunks cutting through values and their fields.
+	var got []StringsStruct
+	u := &xdr.Unmarshaller{Resumable: true}
+	for len(stream) > 0 || u.Remaining() > 0 {
+		cp := u.Checkpoint()
+		var s StringsStruct
+		err := s.UnmarshalXDRFrom(u)
+		if errors.Is(err, xdr.ErrNeedMore) {
+			if len(stream) == 0 {
+				t.Fatal("Unexpected need for more data")
+			}
+			n := 3
+			if n > len(stream) {
+				n = len(stream)
+			}
+			u.Resume(cp, stream[:n])
+			stream = stream[n:]
+			continue
+		}
+		if err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		got = append(got, s)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0, 5, 'a'}, Resumable: true}
+	if u.UnmarshalString(); !errors.Is(u.Error, xdr.ErrNeedMore) || errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected ErrNeedMore, got", u.Error)
+	}
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0, 5, 'a'}}
+	if u.UnmarshalString(); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	var c Chain
+	if err := c.UnmarshalXDR(chainData(xdr.DefaultMaxDepth)); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// A crafted message nesting far deeper than any sane one fails instead
+	// of recursing until the stack is exhausted.
+	if err := c.UnmarshalXDR(chainData(1 << 20)); err != xdr.ErrMaxDepth {
+		t.Fatal("Expected ErrMaxDepth, got", err)
+	}
+
+	u := &xdr.Unmarshaller{Data: chainData(8), MaxDepth: 4}
+	if err := c.UnmarshalXDRFrom(u); err != xdr.ErrMaxDepth {
+		t.Fatal("Expected ErrMaxDepth, got", err)
+	}
+	u = &xdr.Unmarshaller{Data: chainData(100), MaxDepth: -1}
+	if err := c.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// The depth is back to zero after each value.
+	u = &xdr.Unmarshaller{Data: append(chainData(4), chainData(4)...), MaxDepth: 4}
+	for i := 0; i < 2; i++ {
+		if err := c.UnmarshalXDRFrom(u); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+	}
+}
+
+type HashStruct struct {
+	Hash  [32]byte
+	Short [5]uint8
+	N     uint32
+}
+
+func TestFixedByteArrays(t *testing.T) {
+	var h0 HashStruct
+	for i := range h0.Hash {
+		h0.Hash[i] = byte(i)
+	}
+	h0.Short = [5]uint8{1, 2, 3, 4, 5}
+	h0.N = 9
+
+	bs, err := h0.MarshalXDR()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(bs) != 32+8+4 || len(bs) != h0.XDRSize() {
+		t.Fatalf("Expected %d bytes, got %d (XDRSize %d)", 32+8+4, len(bs), h0.XDRSize())
+	}
+	if !bytes.Equal(bs[32:44], []byte{1, 2, 3, 4, 5, 0, 0, 0, 0, 0, 0, 9}) {
+		t.Errorf("Unexpected padding: %x", bs[32:44])
+	}
+
+	var h1 HashStruct
+	if err := h1.UnmarshalXDR(bs); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if h0 != h1 {
+		t.Errorf("Expected %+v, got %+v", h0, h1)
+	}
+
+	if err := h1.UnmarshalXDR(bs[:36]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+type NodeID uint64
+
+type Timestamp int64
+
+type Level uint8
+
+type Label string
+
+type Blob []byte
+
+type NamedStruct struct {
+	ID     NodeID
+	At     Timestamp
+	Lvl    Level
+	Name   Label // max:8
+	Data   Blob
+	IDs    []NodeID
+	Labels []Label // max:4, 8
+	Prev   *NodeID
+}
+
+func TestNamedTypes(t *testing.T) {
+	prev := NodeID(41)
+	n0 := NamedStruct{
+		ID:     42,
+		At:     -1,
+		Lvl:    3,
+		Name:   "node",
+		Data:   Blob{1, 2, 3},
+		IDs:    []NodeID{1, 2},
+		Labels: []Label{"a", "bc"},
+		Prev:   &prev,
+	}
+	bs, err := n0.MarshalXDR()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(bs) != n0.XDRSize() {
+		t.Errorf("Expected %d bytes, got %d", n0.XDRSize(), len(bs))
+	}
+	if !bytes.Equal(bs[:8], []byte{0, 0, 0, 0, 0, 0, 0, 42}) {
+		t.Errorf("Expected NodeID encoded as a hyper, got %x", bs[:8])
+	}
+
+	var n1 NamedStruct
+	if err := n1.UnmarshalXDR(bs); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !reflect.DeepEqual(n0, n1) {
+		t.Errorf("Expected %+v, got %+v", n0, n1)
+	}
+
+	if _, err := (NamedStruct{Name: "too long name"}).MarshalXDR(); err == nil {
+		t.Error("Expected error for oversized Name")
+	}
+}
+
+func TestNewMarshallerAppend(t *testing.T) {
+	o1 := OtherStruct{F1: 1, F2: "one"}
+	o2 := OtherStruct{F1: 2, F2: "two"}
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, 0xca, 0xfe, 0xba, 0xbe)
+	m := xdr.NewMarshaller(buf)
+	for _, o := range []OtherStruct{o1, o2} {
+		m.Grow(o.XDRSize())
+		if err := o.MarshalXDRInto(m); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+	}
+
+	exp := append(append([]byte{0xca, 0xfe, 0xba, 0xbe}, o1.MustMarshalXDR()...), o2.MustMarshalXDR()...)
+	if !bytes.Equal(m.Bytes(), exp) {
+		t.Errorf("Expected %x, got %x", exp, m.Bytes())
+	}
+	if &m.Bytes()[0] != &buf[0] {
+		t.Error("Expected marshalling into the spare capacity of buf")
+	}
+}
+
+func TestStrictHighBytes(t *testing.T) {
+	data := []byte{0, 1, 0, 2, 1, 0, 0, 3}
+
+	u := &xdr.Unmarshaller{Data: data}
+	if v := u.UnmarshalUint8(); v != 2 {
+		t.Errorf("Expected 2, got %d", v)
+	}
+	if v := u.UnmarshalUint16(); v != 3 {
+		t.Errorf("Expected 3, got %d", v)
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: data, Strict: true}
+	u.UnmarshalUint8()
+	if u.Error != xdr.ErrNonZeroHighBytes {
+		t.Fatal("Expected xdr.ErrNonZeroHighBytes, got", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: data[4:], Strict: true}
+	u.UnmarshalUint16()
+	if u.Error != xdr.ErrNonZeroHighBytes {
+		t.Fatal("Expected xdr.ErrNonZeroHighBytes, got", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0xff, 0xfe, 0, 0, 0, 0x80}, Strict: true}
+	if v := u.UnmarshalUint16(); v != 0xfffe {
+		t.Errorf("Expected 0xfffe, got %#x", v)
+	}
+	if v := u.UnmarshalUint8(); v != 0x80 {
+		t.Errorf("Expected 0x80, got %#x", v)
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+}
+
+func TestUnmarshalArray(t *testing.T) {
+	// Two arrays of uint32: {1, 2} and {3}
+	data := []byte{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 3}
+
+	u := &xdr.Unmarshaller{Data: data}
+	var sum uint32
+	for i := 0; i < 2; i++ {
+		u.UnmarshalArray(2)
+		for u.NextElement() {
+			sum += u.UnmarshalUint32()
+		}
+		u.EndArray()
+	}
+	if u.Error != nil {
+		t.Fatal("Unexpected error", u.Error)
+	}
+	if sum != 6 {
+		t.Errorf("Expected sum 6, got %d", sum)
+	}
+
+	// Under-read
+	u = &xdr.Unmarshaller{Data: data}
+	u.UnmarshalArray(0)
+	u.NextElement()
+	u.UnmarshalUint32()
+	u.EndArray()
+	if u.Error != xdr.ErrArrayMismatch {
+		t.Fatal("Expected xdr.ErrArrayMismatch, got", u.Error)
+	}
+
+	// No array in progress
+	u = &xdr.Unmarshaller{Data: data}
+	u.NextElement()
+	if u.Error != xdr.ErrArrayMismatch {
+		t.Fatal("Expected xdr.ErrArrayMismatch, got", u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: data}
+	if n := u.UnmarshalArray(1); n != 0 || u.Error == nil {
+		t.Error("Expected error for count above max")
+	}
+}
+
+func TestMarshalXDRAllocs(t *testing.T) {
+	// Nested structs are marshalled into the parent's Marshaller, so the
+	// only allocation is the output buffer.
+	e := StatusOK
+	o := OptionalStruct{O: &OtherStruct{F1: 1, F2: "nested"}, E: &e}
+	if n := testing.AllocsPerRun(100, func() { o.MarshalXDR() }); n != 1 {
+		t.Errorf("Expected 1 allocation, got %v", n)
+	}
+
+	// Sizing a slice of structs must not allocate per element.
+	b := Batch{Items: make([]Item, 16)}
+	if n := testing.AllocsPerRun(100, func() { b.XDRSize() }); n > 1 {
+		t.Errorf("Expected at most 1 allocation, got %v", n)
+	}
+}
+
+func TestUnmarshalReuse(t *testing.T) {
+	bs := EnumStruct{S: StatusOK, Ss: []Status{StatusFailed, StatusOK}}.MustMarshalXDR()
+
+	e := EnumStruct{Ss: make([]Status, 0, 8)}
+	backing := &e.Ss[:1][0]
+	if err := e.UnmarshalXDR(bs); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if len(e.Ss) != 2 || &e.Ss[0] != backing {
+		t.Error("Expected the existing backing array to be reused")
+	}
+
+	u := &xdr.Unmarshaller{}
+	if n := testing.AllocsPerRun(100, func() {
+		u.Reset(bs)
+		e.UnmarshalXDRFrom(u)
+	}); n != 0 {
+		t.Errorf("Expected no allocations, got %v", n)
+	}
+}
+
+func TestMarshalMax(t *testing.T) {
+	m := xdr.NewMarshallerSize(16)
+	m.MarshalStringMax("abcd", 4)
+	m.MarshalBytesMax([]byte{1, 2}, 2)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+
+	m.MarshalBytesMax([]byte{1, 2, 3}, 2)
+	if !errors.Is(m.Error, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected xdr.ErrElementSizeExceeded, got", m.Error)
+	}
+
+	m = xdr.NewMarshallerSize(16)
+	m.MarshalStringMax("abcde", 4)
+	if !errors.Is(m.Error, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected xdr.ErrElementSizeExceeded, got", m.Error)
+	}
+}
+
+func TestMarshalBytesN(t *testing.T) {
+	scratch := []byte{1, 2, 3, 4, 5, 6}
+	m := xdr.NewMarshallerSize(24)
+	m.MarshalBytesN(scratch, 3)
+	m.MarshalBytesN(scratch, 0)
+	m.MarshalBytesN(scratch, 6)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+	exp := []byte{0, 0, 0, 3, 1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 6, 1, 2, 3, 4, 5, 6, 0, 0}
+	if !bytes.Equal(m.Data, exp) {
+		t.Errorf("Expected %x, got %x", exp, m.Data)
+	}
+
+	for _, n := range []int{-1, 7} {
+		m = xdr.NewMarshallerSize(16)
+		if m.MarshalBytesN(scratch, n); !errors.Is(m.Error, xdr.ErrElementSizeExceeded) {
+			t.Errorf("%d: expected xdr.ErrElementSizeExceeded, got %v", n, m.Error)
+		}
+	}
+}
+
+func TestUint128(t *testing.T) {
+	v := [2]uint64{0x0102030405060708, 0x090a0b0c0d0e0f10}
+	m := xdr.NewMarshallerSize(16)
+	m.MarshalUint128(v)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+	exp := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	if !bytes.Equal(m.Data, exp) {
+		t.Errorf("Expected %x, got %x", exp, m.Data)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if v1 := u.UnmarshalUint128(); v1 != v || u.Error != nil {
+		t.Errorf("Expected %x, got %x (%v)", v, v1, u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Data[:12]}
+	u.UnmarshalUint128()
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+	if u.Offset() != 0 {
+		t.Errorf("Expected nothing consumed, got offset %d", u.Offset())
+	}
+
+	m = xdr.NewMarshallerSize(8)
+	m.MarshalUint128(v)
+	if m.Error != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", m.Error)
+	}
+}
+
+func TestQuadRaw(t *testing.T) {
+	// 1.0 as an IEEE 754 binary128
+	one := [16]byte{0x3f, 0xff}
+	m := xdr.NewMarshallerSize(20)
+	m.MarshalQuadRaw(one)
+	m.MarshalUint32(7)
+	if m.Error != nil {
+		t.Fatal("Unexpected error", m.Error)
+	}
+
+	// The bytes are not reordered, whatever the byte order.
+	u := &xdr.Unmarshaller{Data: m.Data, ByteOrder: binary.LittleEndian}
+	if v := u.UnmarshalQuadRaw(); v != one || u.Error != nil {
+		t.Errorf("Expected %x, got %x (%v)", one, v, u.Error)
+	}
+	if u.Offset() != 16 {
+		t.Errorf("Expected offset 16, got %d", u.Offset())
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Data[:12]}
+	if v := u.UnmarshalQuadRaw(); v != ([16]byte{}) || !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+
+	m = xdr.NewMarshallerSize(8)
+	m.MarshalQuadRaw(one)
+	if m.Error != io.ErrShortBuffer {
+		t.Fatal("Expected io.ErrShortBuffer, got", m.Error)
+	}
+}
+
+func TestUint32At(t *testing.T) {
+	data := []byte{0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3}
+	u := &xdr.Unmarshaller{Data: data}
+	u.UnmarshalUint32()
+
+	// Offsets count from the current position, which is left alone.
+	if v := u.Uint32At(4); v != 3 {
+		t.Errorf("Expected 3, got %d", v)
+	}
+	if v := u.Uint32At(8 - u.Offset()); v != 3 {
+		t.Errorf("Expected 3 at absolute offset 8, got %d", v)
+	}
+	if u.Offset() != 4 || u.UnmarshalUint32() != 2 {
+		t.Error("Expected the position to be unchanged")
+	}
+
+	if u.Uint32At(-12); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+	u = &xdr.Unmarshaller{Data: data}
+	if u.Uint32At(10); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+
+	// Reads past the end ask for more data when resumable, like other reads.
+	u = &xdr.Unmarshaller{Data: data[:8], Resumable: true}
+	cp := u.Checkpoint()
+	if u.Uint32At(8); !errors.Is(u.Error, xdr.ErrNeedMore) {
+		t.Fatal("Expected xdr.ErrNeedMore, got", u.Error)
+	}
+	u.Resume(cp, data[8:])
+	if v := u.Uint32At(8); v != 3 || u.Error != nil {
+		t.Errorf("Expected 3 after resuming, got %d, %v", v, u.Error)
+	}
+	u = &xdr.Unmarshaller{Data: data, Resumable: true}
+	u.UnmarshalUint32()
+	if u.Uint32At(-8); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF before the current position, got", u.Error)
+	}
+}
+
+func TestUnmarshalRawPadded(t *testing.T) {
+	u := &xdr.Unmarshaller{Data: []byte{1, 2, 3, 0, 0, 0, 0, 4}}
+	if v := u.UnmarshalRawPadded(3); !bytes.Equal(v, []byte{1, 2, 3}) {
+		t.Errorf("Expected 010203, got %x", v)
+	}
+	if v := u.UnmarshalUint32(); v != 4 {
+		t.Errorf("Expected aligned read of 4, got %d", v)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{1, 2, 3}}
+	u.UnmarshalRawPadded(3)
+	if !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestUnmarshalFinish(t *testing.T) {
+	bs := append(OtherStruct{F1: 1, F2: "x"}.MustMarshalXDR(), 0, 0, 0, 0)
+
+	var o OtherStruct
+	u := &xdr.Unmarshaller{Data: bs}
+	o.UnmarshalXDRFrom(u)
+	if err := u.Finish(); !errors.Is(err, xdr.ErrTrailingData) {
+		t.Fatal("Expected xdr.ErrTrailingData, got", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: bs[:len(bs)-4]}
+	o.UnmarshalXDRFrom(u)
+	if err := u.Finish(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	u = &xdr.Unmarshaller{Data: bs[:6]}
+	o.UnmarshalXDRFrom(u)
+	if err := u.Finish(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestMarshalNested(t *testing.T) {
+	o0 := OtherStruct{F1: 42, F2: "nested"}
+	m := xdr.NewMarshaller(nil)
+	m.Grow(4 + 4 + o0.XDRSize() + 4 + 4)
+	m.MarshalUint32(1)
+	m.MarshalNested(func(m *xdr.Marshaller) {
+		o0.MarshalXDRInto(m)
+		m.MarshalRaw([]byte{1, 2, 3})
+	})
+	m.MarshalUint32(2)
+	if m.Error != nil {
+		t.Fatal(m.Error)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Bytes()}
+	if v := u.UnmarshalUint32(); v != 1 {
+		t.Fatal("Expected 1, got", v)
+	}
+	n := u.UnmarshalNested()
+	var o1 OtherStruct
+	o1.UnmarshalXDRFrom(n)
+	if o1 != o0 {
+		t.Errorf("%+v != %+v", o1, o0)
+	}
+	if r := n.UnmarshalRemaining(); !bytes.Equal(r, []byte{1, 2, 3}) {
+		t.Errorf("Unexpected nested remainder %v", r)
+	}
+	if v := u.UnmarshalUint32(); v != 2 {
+		t.Fatal("Expected 2, got", v)
+	}
+	if err := u.Finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unknown nested message can be skipped.
+	u = &xdr.Unmarshaller{Data: m.Bytes()}
+	u.UnmarshalUint32()
+	u.UnmarshalNested()
+	if v := u.UnmarshalUint32(); v != 2 || u.Error != nil {
+		t.Fatal("Expected 2, got", v, u.Error)
+	}
+
+	u = &xdr.Unmarshaller{Data: m.Bytes()[:12]}
+	u.UnmarshalUint32()
+	if n := u.UnmarshalNested(); !errors.Is(n.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", n.Error)
+	}
+}
+
+func TestByteOrder(t *testing.T) {
+	o0 := OtherStruct{F1: 0x01020304, F2: "abc"}
+	m := xdr.NewMarshallerSize(o0.XDRSize() + 8)
+	m.ByteOrder = binary.LittleEndian
+	o0.MarshalXDRInto(m)
+	m.MarshalUint64(0x0102030405060708)
+	if m.Error != nil {
+		t.Fatal(m.Error)
+	}
+
+	expected := []byte{
+		4, 3, 2, 1,
+		3, 0, 0, 0, 'a', 'b', 'c', 0,
+		8, 7, 6, 5, 4, 3, 2, 1,
+	}
+	if !bytes.Equal(m.Data, expected) {
+		t.Fatalf("Unexpected encoding %x", m.Data)
+	}
+
+	var o1 OtherStruct
+	u := &xdr.Unmarshaller{Data: m.Data, ByteOrder: binary.LittleEndian}
+	o1.UnmarshalXDRFrom(u)
+	if v := u.UnmarshalUint64(); v != 0x0102030405060708 {
+		t.Errorf("Unexpected uint64 %x", v)
+	}
+	if err := u.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if o1 != o0 {
+		t.Errorf("%+v != %+v", o1, o0)
+	}
+
+	m = xdr.NewMarshallerSize(12)
+	m.ByteOrder = binary.LittleEndian
+	m.MarshalNested(func(m *xdr.Marshaller) { m.MarshalUint64(42) })
+	if !bytes.Equal(m.Data[:4], []byte{8, 0, 0, 0}) {
+		t.Fatalf("Unexpected nested size prefix %x", m.Data[:4])
+	}
+	u = &xdr.Unmarshaller{Data: m.Data, ByteOrder: binary.LittleEndian}
+	if v := u.UnmarshalNested().UnmarshalUint64(); v != 42 {
+		t.Errorf("Expected 42 from the nested message, got %d", v)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{1, 2, 0, 0}, ByteOrder: binary.LittleEndian, Strict: true}
+	if v := u.UnmarshalUint16(); v != 0x0201 || u.Error != nil {
+		t.Errorf("Unexpected uint16 %x, %v", v, u.Error)
+	}
+	u = &xdr.Unmarshaller{Data: []byte{1, 2, 0, 0}, ByteOrder: binary.LittleEndian, Strict: true}
+	if u.UnmarshalUint8(); u.Error != xdr.ErrNonZeroHighBytes {
+		t.Fatal("Expected xdr.ErrNonZeroHighBytes, got", u.Error)
+	}
+}
+
+func TestMarshalXDRBlob(t *testing.T) {
+	o0 := OtherStruct{F1: 1, F2: "blob"}
+	blob := o0.MustMarshalXDR()
+
+	m := xdr.NewMarshallerSize(4 + len(blob))
+	m.MarshalXDRBlob(blob)
+	if m.Error != nil {
+		t.Fatal(m.Error)
+	}
+
+	var o1 OtherStruct
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if err := o1.UnmarshalXDRFrom(u.UnmarshalNested()); err != nil {
+		t.Fatal(err)
+	}
+	if o1 != o0 {
+		t.Errorf("%+v != %+v", o1, o0)
+	}
+
+	m = xdr.NewMarshallerSize(16)
+	m.MarshalXDRBlob([]byte("abc"))
+	if m.Error != xdr.ErrUnalignedBlob {
+		t.Fatal("Expected xdr.ErrUnalignedBlob, got", m.Error)
+	}
+}
+
+func TestFloat16(t *testing.T) {
+	cases := []struct {
+		v    float32
+		bits uint16
+		back float32 // value decoded back, when rounded
+	}{
+		{0, 0x0000, 0},
+		{1, 0x3c00, 0},
+		{-2, 0xc000, 0},
+		{0.5, 0x3800, 0},
+		{65504, 0x7bff, 0},                          // largest normal
+		{float32(math.Ldexp(1, -14)), 0x0400, 0},    // smallest normal
+		{float32(math.Ldexp(1, -24)), 0x0001, 0},    // smallest subnormal
+		{float32(math.Ldexp(1023, -24)), 0x03ff, 0}, // largest subnormal
+
+		// Ties round to even.
+		{float32(1 + math.Ldexp(1, -11)), 0x3c00, 1},
+		{float32(1 + math.Ldexp(3, -11)), 0x3c02, float32(1 + math.Ldexp(1, -9))},
+		{float32(math.Ldexp(1, -25)), 0x0000, 0},
+		{float32(math.Ldexp(3, -25)), 0x0002, float32(math.Ldexp(1, -23))},
+		{float32(math.Ldexp(2047, -25)), 0x0400, float32(math.Ldexp(1, -14))}, // carries into the normals
+		{65520, 0x7c00, float32(math.Inf(1))},                                 // carries into infinity
+
+		// Not ties.
+		{float32(1 + math.Ldexp(1, -11) + math.Ldexp(1, -20)), 0x3c01, float32(1 + math.Ldexp(1, -10))},
+		{float32(math.Ldexp(1, -25) + math.Ldexp(1, -30)), 0x0001, float32(math.Ldexp(1, -24))},
+		{float32(math.Ldexp(1, -26)), 0x0000, 0},
+		{1e6, 0x7c00, float32(math.Inf(1))},
+		{-1e-10, 0x8000, float32(math.Copysign(0, -1))},
+
+		{float32(math.Inf(1)), 0x7c00, 0},
+		{float32(math.Inf(-1)), 0xfc00, 0},
+	}
+
+	for _, tc := range cases {
+		m := xdr.NewMarshallerSize(4)
+		m.MarshalFloat16(tc.v)
+		if m.Error != nil {
+			t.Fatal(m.Error)
+		}
+		if bits := uint16(m.Data[2])<<8 | uint16(m.Data[3]); bits != tc.bits || m.Data[0] != 0 || m.Data[1] != 0 {
+			t.Errorf("%g: encoded as %x, expected %04x", tc.v, m.Data, tc.bits)
+			continue
+		}
+
+		back := tc.back
+		if back == 0 && tc.bits&0x7fff != 0 {
+			back = tc.v
+		}
+		u := &xdr.Unmarshaller{Data: m.Data, Strict: true}
+		if v := u.UnmarshalFloat16(); v != back || math.Signbit(float64(v)) != math.Signbit(float64(back)) || u.Error != nil {
+			t.Errorf("%04x: decoded as %g, expected %g (%v)", tc.bits, v, back, u.Error)
+		}
+	}
+
+	m := xdr.NewMarshallerSize(4)
+	m.MarshalFloat16(float32(math.NaN()))
+	u := &xdr.Unmarshaller{Data: m.Data}
+	if v := u.UnmarshalFloat16(); !math.IsNaN(float64(v)) {
+		t.Errorf("Expected NaN, got %g", v)
+	}
+
+	// Every binary16 value survives the trip through float32.
+	for h := 0; h <= 0xffff; h++ {
+		bs := []byte{0, 0, byte(h >> 8), byte(h)}
+		u := &xdr.Unmarshaller{Data: bs}
+		m := xdr.NewMarshallerSize(4)
+		m.MarshalFloat16(u.UnmarshalFloat16())
+		if h&0x7c00 == 0x7c00 && h&0x3ff != 0 {
+			if m.Data[2]&0x7c != 0x7c || m.Data[2]&0x3|m.Data[3] == 0 {
+				t.Errorf("%04x: NaN encoded as %x", h, m.Data)
+			}
+			continue
+		}
+		if !bytes.Equal(m.Data, bs) {
+			t.Errorf("%04x: encoded back as %x", h, m.Data)
+		}
+	}
+}
+
+func TestNewStrictUnmarshaller(t *testing.T) {
+	bs := OtherStruct{F1: 1, F2: "abc"}.MustMarshalXDR()
+
+	u, err := xdr.NewStrictUnmarshaller(bs)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if !u.Strict {
+		t.Error("Expected a strict Unmarshaller")
+	}
+	var o OtherStruct
+	if err := o.UnmarshalXDRFrom(u); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, l := range []int{1, 2, 3, len(bs) - 1} {
+		if _, err := xdr.NewStrictUnmarshaller(bs[:l]); !errors.Is(err, xdr.ErrUnalignedData) {
+			t.Errorf("%d bytes: expected xdr.ErrUnalignedData, got %v", l, err)
+		}
+	}
+}
+
+func TestUnmarshallerClone(t *testing.T) {
+	bs := append(OtherStruct{F1: 1, F2: "abc"}.MustMarshalXDR(), 0, 0, 0, 7)
+	u := &xdr.Unmarshaller{Data: bs}
+
+	// A failed attempt leaves u untouched.
+	c := u.Clone()
+	var s OptionalStruct
+	if err := s.UnmarshalXDRFrom(c); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if u.Error != nil || u.Offset() != 0 || len(u.Data) != len(bs) {
+		t.Fatal("Clone affected the original", u.Error, u.Offset())
+	}
+
+	c = u.Clone()
+	var o OtherStruct
+	if err := o.UnmarshalXDRFrom(c); err != nil {
+		t.Fatal(err)
+	}
+	*u = *c
+	if v := u.UnmarshalUint32(); v != 7 || u.Error != nil {
+		t.Fatal("Expected 7, got", v, u.Error)
+	}
+
+	// Array iteration state is not shared.
+	u = &xdr.Unmarshaller{Data: []byte{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2}}
+	u.UnmarshalArray(0)
+	c = u.Clone()
+	for c.NextElement() {
+		c.UnmarshalUint32()
+	}
+	if !u.NextElement() {
+		t.Fatal("Clone affected the original's arrays")
+	}
+}
+
+// RecordV1 is the first version of a versioned record.
+//
+//xdr:versioned
+type RecordV1 struct {
+	ID   uint32
+	Name string
+}
+
+// RecordV2 is RecordV1 with fields appended.
+//
+//xdr:versioned
+type RecordV2 struct {
+	ID    uint32
+	Name  string
+	Tags  []string
+	Owner *OtherStruct
+}
+
+// Envelope holds a versioned record followed by more data.
+type Envelope struct {
+	Record RecordV1
+	After  uint32
+}
+
+func TestVersionedStruct(t *testing.T) {
+	v2 := RecordV2{ID: 1, Name: "two", Tags: []string{"a", "b"}, Owner: &OtherStruct{F1: 2}}
+	bs := v2.MustMarshalXDR()
+	if l := len(bs); l != v2.XDRSize() || int(binary.BigEndian.Uint32(bs)) != l-4 {
+		t.Fatalf("Unexpected size prefix %x for %d bytes", bs[:4], l)
+	}
+
+	// An old decoder skips the new fields.
+	var v1 RecordV1
+	if err := v1.UnmarshalXDR(bs); err != nil {
+		t.Fatal(err)
+	}
+	if v1.ID != 1 || v1.Name != "two" {
+		t.Errorf("Unexpected %+v", v1)
+	}
+
+	// A new decoder leaves the missing fields zero.
+	bs = RecordV1{ID: 3, Name: "one"}.MustMarshalXDR()
+	v2 = RecordV2{Tags: []string{"stale"}}
+	if err := v2.UnmarshalXDR(bs); err != nil {
+		t.Fatal(err)
+	}
+	if v2.ID != 3 || v2.Name != "one" || v2.Tags != nil || v2.Owner != nil {
+		t.Errorf("Unexpected %+v", v2)
+	}
+
+	// The data following a versioned struct is found past its new fields.
+	r := RecordV2{ID: 4, Name: "four", Tags: []string{"new"}}
+	m := xdr.NewMarshallerSize(r.XDRSize() + 4)
+	r.MarshalXDRInto(m)
+	m.MarshalUint32(42)
+	var e Envelope
+	if err := e.UnmarshalXDR(m.Data); err != nil {
+		t.Fatal(err)
+	}
+	if e.Record.ID != 4 || e.After != 42 {
+		t.Errorf("Unexpected %+v", e)
+	}
+
+	// Truncation within a field is still an error.
+	bs = RecordV1{ID: 3, Name: "one"}.MustMarshalXDR()
+	bs[3] = 6
+	if err := v2.UnmarshalXDR(bs[:10]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if err := v2.UnmarshalXDR(bs[:2]); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestMarshalTime(t *testing.T) {
+	for _, t0 := range []time.Time{
+		time.Unix(0, 0),
+		time.Date(2024, 2, 29, 12, 34, 56, 789012345, time.UTC),
+		time.Date(1960, 1, 1, 0, 0, 0, 1, time.UTC),
+		time.Date(2500, 1, 1, 0, 0, 0, 999999999, time.FixedZone("X", 3600)),
+		time.Now(),
+	} {
+		m := xdr.NewMarshallerSize(12)
+		m.MarshalTime(t0)
+		if m.Error != nil {
+			t.Fatal(m.Error)
+		}
+		if s := int64(binary.BigEndian.Uint64(m.Data)); s != t0.Unix() {
+			t.Errorf("%v: encoded %d seconds", t0, s)
+		}
+
+		u := &xdr.Unmarshaller{Data: m.Data}
+		if t1 := u.UnmarshalTime(); !t1.Equal(t0) || t1.Location() != time.UTC || u.Error != nil {
+			t.Errorf("%v: decoded as %v (%v)", t0, t1, u.Error)
+		}
+	}
+
+	u := &xdr.Unmarshaller{Data: []byte{0, 0, 0, 0, 0, 0, 0, 1, 0x3b, 0x9a, 0xca, 0x00}}
+	if u.UnmarshalTime(); u.Error != xdr.ErrInvalidTime {
+		t.Fatal("Expected xdr.ErrInvalidTime, got", u.Error)
+	}
+	u = &xdr.Unmarshaller{Data: make([]byte, 8)}
+	if u.UnmarshalTime(); !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", u.Error)
+	}
+}
+
+func TestBigEndianHelpers(t *testing.T) {
+	m := xdr.NewMarshallerSize(12)
+	m.MarshalUint32(0x01020304)
+	m.MarshalUint64(0x05060708090a0b0c)
+
+	// The helpers lay integers out exactly as the Marshaller does.
+	bs := make([]byte, 12)
+	xdr.PutUint32BE(bs, 0x01020304)
+	xdr.PutUint64BE(bs[4:], 0x05060708090a0b0c)
+	if !bytes.Equal(bs, m.Data) {
+		t.Errorf("Expected %x, got %x", m.Data, bs)
+	}
+	if v := xdr.Uint32BE(bs); v != 0x01020304 {
+		t.Errorf("Expected 0x01020304, got %#x", v)
+	}
+	if v := xdr.Uint64BE(bs[4:]); v != 0x05060708090a0b0c {
+		t.Errorf("Expected 0x05060708090a0b0c, got %#x", v)
+	}
+
+	xdr.PutUint16BE(bs, 0xfffe)
+	if bs[0] != 0xff || bs[1] != 0xfe || xdr.Uint16BE(bs) != 0xfffe {
+		t.Errorf("Unexpected uint16 layout %x", bs[:2])
+	}
+	if binary.BigEndian.Uint16(bs) != xdr.Uint16BE(bs) {
+		t.Error("Expected agreement with encoding/binary")
+	}
+}
+
+func TestMarshalUnion(t *testing.T) {
+	m := xdr.NewMarshaller(nil)
+	m.Grow(4 + 12 + 4 + 4 + 8)
+	m.MarshalUnion(-1, func(m *xdr.Marshaller) {
+		m.MarshalString("failed")
+	})
+	m.MarshalUnion(0, nil)
+	m.MarshalUnion(1, func(m *xdr.Marshaller) {
+		m.MarshalUint64(42)
+	})
+	if m.Error != nil {
+		t.Fatal(m.Error)
+	}
+	if l := len(m.Bytes()); l != 4+12+4+4+8 {
+		t.Fatal("Expected 32 bytes, got", l)
+	}
+
+	u := &xdr.Unmarshaller{Data: m.Bytes()}
+	var got []string
+	for len(u.Data) > 0 {
+		switch d := u.UnmarshalUnion(); d {
+		case -1:
+			got = append(got, u.UnmarshalString())
+		case 0:
+			got = append(got, "void")
+		case 1:
+			got = append(got, strconv.FormatUint(u.UnmarshalUint64(), 10))
+		default:
+			t.Fatal("Unexpected discriminant", d)
+		}
+	}
+	if err := u.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"failed", "void", "42"}) {
+		t.Errorf("Unexpected arms %q", got)
+	}
+
+	u = &xdr.Unmarshaller{Data: []byte{0xff, 0xff}}
+	if d := u.UnmarshalUnion(); d != 0 || !errors.Is(u.Error, io.ErrUnexpectedEOF) {
+		t.Error("Expected io.ErrUnexpectedEOF, got", d, u.Error)
+	}
 }
@@ -5,9 +5,105 @@
 package xdr_test
 
 import (
+	"io"
+	"math"
+	"strconv"
+	"unsafe"
+
 	"dario.cat/xdr"
 )
 
+// XDRSize returns the XDR encoded form's size.
+func (o Status) XDRSize() int {
+	return 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Status) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Status) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the enum using the provided Marshaller.
+func (o Status) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalInt32(int32(o))
+	return m.Error
+}
+
+// EncodeXDR writes the enum to the provided Encoder.
+func (o Status) EncodeXDR(e *xdr.Encoder) error {
+	return e.EncodeInt32(int32(o))
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// enum.
+func (o *Status) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the enum using the provided Unmarshaller.
+// Values other than the declared Status constants are rejected.
+func (o *Status) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	v := Status(u.UnmarshalInt32())
+	if u.Error != nil {
+		return u.Error
+	}
+	switch v {
+	case StatusOK, StatusFailed, StatusUnknown:
+	default:
+		return u.Fail(xdr.InvalidEnumValue("Status", int32(v)))
+	}
+	*o = v
+	return nil
+}
+
+// String returns the name of the Status constant equal to o, or the
+// numeric value for unknown values.
+func (o Status) String() string {
+	switch o {
+	case StatusOK:
+		return "StatusOK"
+	case StatusFailed:
+		return "StatusFailed"
+	case StatusUnknown:
+		return "StatusUnknown"
+	}
+	return "Status(" + strconv.FormatInt(int64(o), 10) + ")"
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Status) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Status) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Status) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
 /*
 
 TestStruct Structure:
@@ -102,8 +198,8 @@ struct TestStruct {
 // XDRSize returns the XDR encoded form's size.
 func (o TestStruct) XDRSize() int {
 	return 4 + 8 + 4 + 4 + 4 + 4 + 4 + 4 + 8 + 8 +
-		4 + len(o.BS) + xdr.Padding(len(o.BS)) +
-		4 + len(o.S) + xdr.Padding(len(o.S)) +
+		xdr.BytesSize(len(o.BS)) +
+		xdr.StringSize(o.S) +
 		o.C.XDRSize() +
 		4 + xdr.SizeOfSlice(o.SS) +
 		o.ES.XDRSize() +
@@ -113,9 +209,8 @@ func (o TestStruct) XDRSize() int {
 
 // MarshalXDR returns the XDR encoding.
 func (o TestStruct) MarshalXDR() ([]byte, error) {
-	buf := make([]byte, o.XDRSize())
-	m := &xdr.Marshaller{Data: buf}
-	return buf, o.MarshalXDRInto(m)
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
 }
 
 // MustMarshalXDR returns the XDR encoding. MustMarshalXDR
@@ -173,15 +268,72 @@ func (o TestStruct) MarshalXDRInto(m *xdr.Marshaller) error {
 	return m.Error
 }
 
+// EncodeXDR writes the struct to the provided Encoder.
+func (o TestStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeBool(o.B)
+	e.EncodeUint64(uint64(o.I))
+	e.EncodeUint8(uint8(o.I8))
+	e.EncodeUint8(o.UI8)
+	e.EncodeUint16(uint16(o.I16))
+	e.EncodeUint16(o.UI16)
+	e.EncodeUint32(uint32(o.I32))
+	e.EncodeUint32(o.UI32)
+	e.EncodeUint64(uint64(o.I64))
+	e.EncodeUint64(o.UI64)
+	if l := len(o.BS); l > 1024 {
+		return xdr.ElementSizeExceeded("BS", l, 1024)
+	}
+	e.EncodeBytes(o.BS)
+	if l := len(o.S); l > 1024 {
+		return xdr.ElementSizeExceeded("S", l, 1024)
+	}
+	e.EncodeString(o.S)
+	if err := o.C.EncodeXDR(e); err != nil {
+		return err
+	}
+	if l := len(o.SS); l > 1024 {
+		return xdr.ElementSizeExceeded("SS", l, 1024)
+	}
+	e.EncodeUint32(uint32(len(o.SS)))
+	for i := range o.SS {
+		e.EncodeString(o.SS[i])
+	}
+	if err := o.ES.EncodeXDR(e); err != nil {
+		return err
+	}
+	if err := o.OS.EncodeXDR(e); err != nil {
+		return err
+	}
+	e.EncodeUint32(uint32(len(o.OSs)))
+	for i := range o.OSs {
+		if err := o.OSs[i].EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
 // UnmarshalXDR parses the XDR-encoded data and stores the result in the
 // struct.
 func (o *TestStruct) UnmarshalXDR(bs []byte) error {
-	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *TestStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
 	return o.UnmarshalXDRFrom(u)
 }
 
 // UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
 func (o *TestStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
 	o.B = u.UnmarshalBool()
 	o.I = int(u.UnmarshalUint64())
 	o.I8 = int8(u.UnmarshalUint8())
@@ -192,50 +344,167 @@ func (o *TestStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 	o.UI32 = u.UnmarshalUint32()
 	o.I64 = int64(u.UnmarshalUint64())
 	o.UI64 = u.UnmarshalUint64()
+	if _, err := xdr.CheckLength("BS", u.PeekUint32(), 1024); err != nil {
+		return u.Fail(err)
+	}
 	o.BS = u.UnmarshalBytesMax(1024)
+	if _, err := xdr.CheckLength("S", u.PeekUint32(), 1024); err != nil {
+		return u.Fail(err)
+	}
 	o.S = u.UnmarshalStringMax(1024)
-	(&o.C).UnmarshalXDRFrom(u)
-	_SSSize := int(u.UnmarshalUint32())
-	if _SSSize < 0 {
-		return xdr.ElementSizeExceeded("SS", _SSSize, 1024)
-	} else if _SSSize == 0 {
+	if err := (&o.C).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	_SSSize := u.UnmarshalLength("SS", 1024)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _SSSize == 0 {
 		o.SS = nil
 	} else {
-		if _SSSize > 1024 {
-			return xdr.ElementSizeExceeded("SS", _SSSize, 1024)
+		if !u.Require(_SSSize, 4) {
+			return u.Error
 		}
-		if _SSSize <= len(o.SS) {
+		if _SSSize <= cap(o.SS) {
 			for i := _SSSize; i < len(o.SS); i++ {
 				o.SS[i] = ""
 			}
 			o.SS = o.SS[:_SSSize]
 		} else {
-			o.SS = make([]string, _SSSize)
+			_SSCap := u.Capacity("TestStruct.SS", _SSSize)
+			if !u.Alloc(_SSCap * int(unsafe.Sizeof(o.SS[0]))) {
+				return u.Error
+			}
+			o.SS = make([]string, _SSSize, _SSCap)
 		}
 		for i := range o.SS {
 			o.SS[i] = u.UnmarshalString()
 		}
 	}
-	(&o.ES).UnmarshalXDRFrom(u)
-	(&o.OS).UnmarshalXDRFrom(u)
-	_OSsSize := int(u.UnmarshalUint32())
-	if _OSsSize < 0 {
-		return xdr.ElementSizeExceeded("OSs", _OSsSize, 0)
-	} else if _OSsSize == 0 {
+	if err := (&o.ES).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	if err := (&o.OS).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	_OSsSize := u.UnmarshalLength("OSs", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _OSsSize == 0 {
 		o.OSs = nil
 	} else {
-		if _OSsSize <= len(o.OSs) {
+		if !u.Require(_OSsSize, 8) {
+			return u.Error
+		}
+		if _OSsSize <= cap(o.OSs) {
 			o.OSs = o.OSs[:_OSsSize]
 		} else {
-			o.OSs = make([]OtherStruct, _OSsSize)
+			_OSsCap := u.Capacity("TestStruct.OSs", _OSsSize)
+			if !u.Alloc(_OSsCap * int(unsafe.Sizeof(o.OSs[0]))) {
+				return u.Error
+			}
+			o.OSs = make([]OtherStruct, _OSsSize, _OSsCap)
 		}
 		for i := range o.OSs {
-			(&o.OSs[i]).UnmarshalXDRFrom(u)
+			if err := (&o.OSs[i]).UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
 		}
 	}
 	return u.Error
 }
 
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o TestStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o TestStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *TestStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o TestStruct) Equal(p TestStruct) bool {
+	if o.B != p.B {
+		return false
+	}
+	if o.I != p.I {
+		return false
+	}
+	if o.I8 != p.I8 {
+		return false
+	}
+	if o.UI8 != p.UI8 {
+		return false
+	}
+	if o.I16 != p.I16 {
+		return false
+	}
+	if o.UI16 != p.UI16 {
+		return false
+	}
+	if o.I32 != p.I32 {
+		return false
+	}
+	if o.UI32 != p.UI32 {
+		return false
+	}
+	if o.I64 != p.I64 {
+		return false
+	}
+	if o.UI64 != p.UI64 {
+		return false
+	}
+	if string(o.BS) != string(p.BS) {
+		return false
+	}
+	if o.S != p.S {
+		return false
+	}
+	if o.C != p.C {
+		return false
+	}
+	if len(o.SS) != len(p.SS) {
+		return false
+	}
+	for i := range o.SS {
+		if o.SS[i] != p.SS[i] {
+			return false
+		}
+	}
+	if !o.ES.Equal(p.ES) {
+		return false
+	}
+	if !o.OS.Equal(p.OS) {
+		return false
+	}
+	if len(o.OSs) != len(p.OSs) {
+		return false
+	}
+	for i := range o.OSs {
+		if !o.OSs[i].Equal(p.OSs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 /*
 
 EmptyStruct Structure:
@@ -268,6 +537,11 @@ func (o EmptyStruct) MarshalXDRInto(m *xdr.Marshaller) error {
 	return nil
 }
 
+// EncodeXDR writes the struct to the provided Encoder.
+func (o EmptyStruct) EncodeXDR(e *xdr.Encoder) error {
+	return nil
+}
+
 // UnmarshalXDR parses the XDR-encoded data and stores the result in the
 // struct.
 func (o *EmptyStruct) UnmarshalXDR(bs []byte) error {
@@ -279,6 +553,34 @@ func (o *EmptyStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 	return nil
 }
 
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o EmptyStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o EmptyStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *EmptyStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, which they always are as the
+// struct has no encoded fields.
+func (o EmptyStruct) Equal(p EmptyStruct) bool {
+	return true
+}
+
 /*
 
 OtherStruct Structure:
@@ -304,14 +606,13 @@ struct OtherStruct {
 // XDRSize returns the XDR encoded form's size.
 func (o OtherStruct) XDRSize() int {
 	return 4 +
-		4 + len(o.F2) + xdr.Padding(len(o.F2))
+		xdr.StringSize(o.F2)
 }
 
 // MarshalXDR returns the XDR encoding.
 func (o OtherStruct) MarshalXDR() ([]byte, error) {
-	buf := make([]byte, o.XDRSize())
-	m := &xdr.Marshaller{Data: buf}
-	return buf, o.MarshalXDRInto(m)
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
 }
 
 // MustMarshalXDR returns the XDR encoding. MustMarshalXDR
@@ -331,16 +632,3061 @@ func (o OtherStruct) MarshalXDRInto(m *xdr.Marshaller) error {
 	return m.Error
 }
 
+// EncodeXDR writes the struct to the provided Encoder.
+func (o OtherStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint32(o.F1)
+	e.EncodeString(o.F2)
+	return e.Err()
+}
+
 // UnmarshalXDR parses the XDR-encoded data and stores the result in the
 // struct.
 func (o *OtherStruct) UnmarshalXDR(bs []byte) error {
-	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *OtherStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
 	return o.UnmarshalXDRFrom(u)
 }
 
 // UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
 func (o *OtherStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
 	o.F1 = u.UnmarshalUint32()
 	o.F2 = u.UnmarshalString()
 	return u.Error
 }
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o OtherStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o OtherStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *OtherStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o OtherStruct) Equal(p OtherStruct) bool {
+	if o.F1 != p.F1 {
+		return false
+	}
+	if o.F2 != p.F2 {
+		return false
+	}
+	return true
+}
+
+/*
+
+StringsStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Number of Tags                         |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+/                                                               /
+\                  Tags (length + padded data)                  \
+/                                                               /
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct StringsStruct {
+	string Tags<>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o StringsStruct) XDRSize() int {
+	return 4 + xdr.SizeOfSlice(o.Tags)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o StringsStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o StringsStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o StringsStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		m.MarshalString(o.Tags[i])
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o StringsStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		e.EncodeString(o.Tags[i])
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *StringsStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *StringsStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *StringsStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	_TagsSize := u.UnmarshalLength("Tags", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _TagsSize == 0 {
+		o.Tags = nil
+	} else {
+		if !u.Require(_TagsSize, 4) {
+			return u.Error
+		}
+		if _TagsSize <= cap(o.Tags) {
+			for i := _TagsSize; i < len(o.Tags); i++ {
+				o.Tags[i] = ""
+			}
+			o.Tags = o.Tags[:_TagsSize]
+		} else {
+			_TagsCap := u.Capacity("StringsStruct.Tags", _TagsSize)
+			if !u.Alloc(_TagsCap * int(unsafe.Sizeof(o.Tags[0]))) {
+				return u.Error
+			}
+			o.Tags = make([]string, _TagsSize, _TagsCap)
+		}
+		for i := range o.Tags {
+			o.Tags[i] = u.UnmarshalString()
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o StringsStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o StringsStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *StringsStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o StringsStruct) Equal(p StringsStruct) bool {
+	if len(o.Tags) != len(p.Tags) {
+		return false
+	}
+	for i := range o.Tags {
+		if o.Tags[i] != p.Tags[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+Batch Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Number of Items                        |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                 Zero or more Item Structures                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct Batch {
+	Item Items<>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o Batch) XDRSize() int {
+	return 4 + xdr.SizeOfSlice(o.Items)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Batch) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Batch) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o Batch) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(uint32(len(o.Items)))
+	for i := range o.Items {
+		if err := o.Items[i].MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o Batch) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint32(uint32(len(o.Items)))
+	for i := range o.Items {
+		if err := o.Items[i].EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *Batch) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *Batch) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *Batch) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	_ItemsSize := u.UnmarshalLength("Items", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _ItemsSize == 0 {
+		o.Items = nil
+	} else {
+		if !u.Require(_ItemsSize, 4) {
+			return u.Error
+		}
+		if _ItemsSize <= cap(o.Items) {
+			o.Items = o.Items[:_ItemsSize]
+		} else {
+			_ItemsCap := u.Capacity("Batch.Items", _ItemsSize)
+			if !u.Alloc(_ItemsCap * int(unsafe.Sizeof(o.Items[0]))) {
+				return u.Error
+			}
+			o.Items = make([]Item, _ItemsSize, _ItemsCap)
+		}
+		for i := range o.Items {
+			if err := (&o.Items[i]).UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Batch) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Batch) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Batch) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o Batch) Equal(p Batch) bool {
+	if len(o.Items) != len(p.Items) {
+		return false
+	}
+	for i := range o.Items {
+		if !o.Items[i].Equal(p.Items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+Item Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Number of Tags                         |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+/                                                               /
+\                  Tags (length + padded data)                  \
+/                                                               /
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct Item {
+	string Tags<2>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o Item) XDRSize() int {
+	return 4 + xdr.SizeOfSlice(o.Tags)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Item) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Item) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o Item) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Tags); l > 2 {
+		return xdr.ElementSizeExceeded("Tags", l, 2)
+	}
+	m.MarshalUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		m.MarshalString(o.Tags[i])
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o Item) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Tags); l > 2 {
+		return xdr.ElementSizeExceeded("Tags", l, 2)
+	}
+	e.EncodeUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		e.EncodeString(o.Tags[i])
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *Item) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *Item) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *Item) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	_TagsSize := u.UnmarshalLength("Tags", 2)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _TagsSize == 0 {
+		o.Tags = nil
+	} else {
+		if !u.Require(_TagsSize, 4) {
+			return u.Error
+		}
+		if _TagsSize <= cap(o.Tags) {
+			for i := _TagsSize; i < len(o.Tags); i++ {
+				o.Tags[i] = ""
+			}
+			o.Tags = o.Tags[:_TagsSize]
+		} else {
+			_TagsCap := u.Capacity("Item.Tags", _TagsSize)
+			if !u.Alloc(_TagsCap * int(unsafe.Sizeof(o.Tags[0]))) {
+				return u.Error
+			}
+			o.Tags = make([]string, _TagsSize, _TagsCap)
+		}
+		for i := range o.Tags {
+			o.Tags[i] = u.UnmarshalString()
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Item) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Item) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Item) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o Item) Equal(p Item) bool {
+	if len(o.Tags) != len(p.Tags) {
+		return false
+	}
+	for i := range o.Tags {
+		if o.Tags[i] != p.Tags[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+EnumStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                               S                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                         Number of Ss                          |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+|                         Ss (n items)                          |
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct EnumStruct {
+	Status S;
+	Status Ss<8>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o EnumStruct) XDRSize() int {
+	return o.S.XDRSize() +
+		4 + xdr.SizeOfSlice(o.Ss)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o EnumStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o EnumStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o EnumStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	if err := o.S.MarshalXDRInto(m); err != nil {
+		return err
+	}
+	if l := len(o.Ss); l > 8 {
+		return xdr.ElementSizeExceeded("Ss", l, 8)
+	}
+	m.MarshalUint32(uint32(len(o.Ss)))
+	for i := range o.Ss {
+		if err := o.Ss[i].MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o EnumStruct) EncodeXDR(e *xdr.Encoder) error {
+	if err := o.S.EncodeXDR(e); err != nil {
+		return err
+	}
+	if l := len(o.Ss); l > 8 {
+		return xdr.ElementSizeExceeded("Ss", l, 8)
+	}
+	e.EncodeUint32(uint32(len(o.Ss)))
+	for i := range o.Ss {
+		if err := o.Ss[i].EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *EnumStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *EnumStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *EnumStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := (&o.S).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	_SsSize := u.UnmarshalLength("Ss", 8)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _SsSize == 0 {
+		o.Ss = nil
+	} else {
+		if !u.Require(_SsSize, 4) {
+			return u.Error
+		}
+		if _SsSize <= cap(o.Ss) {
+			o.Ss = o.Ss[:_SsSize]
+		} else {
+			_SsCap := u.Capacity("EnumStruct.Ss", _SsSize)
+			if !u.Alloc(_SsCap * int(unsafe.Sizeof(o.Ss[0]))) {
+				return u.Error
+			}
+			o.Ss = make([]Status, _SsSize, _SsCap)
+		}
+		for i := range o.Ss {
+			if err := (&o.Ss[i]).UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o EnumStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o EnumStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *EnumStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o EnumStruct) Equal(p EnumStruct) bool {
+	if o.S != p.S {
+		return false
+	}
+	if len(o.Ss) != len(p.Ss) {
+		return false
+	}
+	for i := range o.Ss {
+		if o.Ss[i] != p.Ss[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+union Result switch (Status Code) {
+case StatusOK:
+	OtherStruct Value;
+case StatusFailed:
+	Failure Value;
+case StatusUnknown:
+	void;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o Result) XDRSize() int {
+	switch o.Code {
+	case StatusOK:
+		if v, ok := o.Value.(*OtherStruct); ok {
+			return 4 + v.XDRSize()
+		}
+	case StatusFailed:
+		if v, ok := o.Value.(*Failure); ok {
+			return 4 + v.XDRSize()
+		}
+	}
+	return 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Result) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Result) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the union using the provided Marshaller.
+func (o Result) MarshalXDRInto(m *xdr.Marshaller) error {
+	switch o.Code {
+	case StatusOK:
+		v, ok := o.Value.(*OtherStruct)
+		if !ok {
+			return xdr.InvalidUnionArm("Result", o.Code)
+		}
+		if err := o.Code.MarshalXDRInto(m); err != nil {
+			return err
+		}
+		if err := v.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	case StatusFailed:
+		v, ok := o.Value.(*Failure)
+		if !ok {
+			return xdr.InvalidUnionArm("Result", o.Code)
+		}
+		if err := o.Code.MarshalXDRInto(m); err != nil {
+			return err
+		}
+		if err := v.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	case StatusUnknown:
+		if err := o.Code.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	default:
+		return xdr.InvalidUnionArm("Result", o.Code)
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the union to the provided Encoder.
+func (o Result) EncodeXDR(e *xdr.Encoder) error {
+	switch o.Code {
+	case StatusOK:
+		v, ok := o.Value.(*OtherStruct)
+		if !ok {
+			return xdr.InvalidUnionArm("Result", o.Code)
+		}
+		if err := o.Code.EncodeXDR(e); err != nil {
+			return err
+		}
+		if err := v.EncodeXDR(e); err != nil {
+			return err
+		}
+	case StatusFailed:
+		v, ok := o.Value.(*Failure)
+		if !ok {
+			return xdr.InvalidUnionArm("Result", o.Code)
+		}
+		if err := o.Code.EncodeXDR(e); err != nil {
+			return err
+		}
+		if err := v.EncodeXDR(e); err != nil {
+			return err
+		}
+	case StatusUnknown:
+		if err := o.Code.EncodeXDR(e); err != nil {
+			return err
+		}
+	default:
+		return xdr.InvalidUnionArm("Result", o.Code)
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// union.
+func (o *Result) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the union using the provided Unmarshaller.
+func (o *Result) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := (&o.Code).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	if u.Error != nil {
+		return u.Error
+	}
+	switch o.Code {
+	case StatusOK:
+		v, ok := o.Value.(*OtherStruct)
+		if !ok {
+			v = new(OtherStruct)
+		}
+		if err := v.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+		o.Value = v
+	case StatusFailed:
+		v, ok := o.Value.(*Failure)
+		if !ok {
+			v = new(Failure)
+		}
+		if err := v.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+		o.Value = v
+	case StatusUnknown:
+		o.Value = nil
+	default:
+		u.Fail(xdr.InvalidUnionArm("Result", o.Code))
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Result) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Result) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Result) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p have the same discriminant and equal arm
+// values.
+func (o Result) Equal(p Result) bool {
+	if o.Code != p.Code {
+		return false
+	}
+	switch o.Code {
+	case StatusOK:
+		a, _ := o.Value.(*OtherStruct)
+		b, _ := p.Value.(*OtherStruct)
+		return a == b || a != nil && b != nil && a.Equal(*b)
+	case StatusFailed:
+		a, _ := o.Value.(*Failure)
+		b, _ := p.Value.(*Failure)
+		return a == b || a != nil && b != nil && a.Equal(*b)
+	}
+	return true
+}
+
+/*
+
+Failure Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                 Reason (length + padded data)                 \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct Failure {
+	string Reason<64>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o Failure) XDRSize() int {
+	return xdr.StringSize(o.Reason)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Failure) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Failure) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o Failure) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Reason); l > 64 {
+		return xdr.ElementSizeExceeded("Reason", l, 64)
+	}
+	m.MarshalString(o.Reason)
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o Failure) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Reason); l > 64 {
+		return xdr.ElementSizeExceeded("Reason", l, 64)
+	}
+	e.EncodeString(o.Reason)
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *Failure) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *Failure) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *Failure) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if _, err := xdr.CheckLength("Reason", u.PeekUint32(), 64); err != nil {
+		return u.Fail(err)
+	}
+	o.Reason = u.UnmarshalStringMax(64)
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Failure) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Failure) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Failure) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o Failure) Equal(p Failure) bool {
+	if o.Reason != p.Reason {
+		return false
+	}
+	return true
+}
+
+/*
+
+OptionalStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Has N (V=0 or 1)                       |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                               N                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Has S (V=0 or 1)                       |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                   S (length + padded data)                    \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Has O (V=0 or 1)                       |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                     OtherStruct Structure                     \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Has E (V=0 or 1)                       |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                               E                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct OptionalStruct {
+	unsigned int *N;
+	string *S<16>;
+	OtherStruct *O;
+	Status *E;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o OptionalStruct) XDRSize() int {
+	s := 4 + 4 + 4 + 4
+	if o.N != nil {
+		s += 4
+	}
+	if o.S != nil {
+		s += xdr.StringSize(*o.S)
+	}
+	if o.O != nil {
+		s += o.O.XDRSize()
+	}
+	if o.E != nil {
+		s += o.E.XDRSize()
+	}
+	return s
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o OptionalStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o OptionalStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o OptionalStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalBool(o.N != nil)
+	if o.N != nil {
+		m.MarshalUint32(*o.N)
+	}
+	m.MarshalBool(o.S != nil)
+	if o.S != nil {
+		if l := len(*o.S); l > 16 {
+			return xdr.ElementSizeExceeded("S", l, 16)
+		}
+		m.MarshalString(*o.S)
+	}
+	m.MarshalBool(o.O != nil)
+	if o.O != nil {
+		if err := o.O.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	m.MarshalBool(o.E != nil)
+	if o.E != nil {
+		if err := o.E.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o OptionalStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeBool(o.N != nil)
+	if o.N != nil {
+		e.EncodeUint32(*o.N)
+	}
+	e.EncodeBool(o.S != nil)
+	if o.S != nil {
+		if l := len(*o.S); l > 16 {
+			return xdr.ElementSizeExceeded("S", l, 16)
+		}
+		e.EncodeString(*o.S)
+	}
+	e.EncodeBool(o.O != nil)
+	if o.O != nil {
+		if err := o.O.EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	e.EncodeBool(o.E != nil)
+	if o.E != nil {
+		if err := o.E.EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *OptionalStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *OptionalStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *OptionalStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if u.UnmarshalBool() {
+		if o.N == nil {
+			o.N = new(uint32)
+		}
+		*o.N = u.UnmarshalUint32()
+	} else {
+		o.N = nil
+	}
+	if u.UnmarshalBool() {
+		if o.S == nil {
+			o.S = new(string)
+		}
+		if _, err := xdr.CheckLength("S", u.PeekUint32(), 16); err != nil {
+			return u.Fail(err)
+		}
+		*o.S = u.UnmarshalStringMax(16)
+	} else {
+		o.S = nil
+	}
+	if u.UnmarshalBool() {
+		if o.O == nil {
+			o.O = new(OtherStruct)
+		}
+		if err := o.O.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+	} else {
+		o.O = nil
+	}
+	if u.UnmarshalBool() {
+		if o.E == nil {
+			o.E = new(Status)
+		}
+		if err := o.E.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+	} else {
+		o.E = nil
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o OptionalStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o OptionalStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *OptionalStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o OptionalStruct) Equal(p OptionalStruct) bool {
+	if (o.N == nil) != (p.N == nil) || o.N != nil && *o.N != *p.N {
+		return false
+	}
+	if (o.S == nil) != (p.S == nil) || o.S != nil && *o.S != *p.S {
+		return false
+	}
+	if (o.O == nil) != (p.O == nil) || o.O != nil && !o.O.Equal(*p.O) {
+		return false
+	}
+	if (o.E == nil) != (p.E == nil) || o.E != nil && *o.E != *p.E {
+		return false
+	}
+	return true
+}
+
+/*
+
+TaggedStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Name (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Blob (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Number of Tags                         |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+/                                                               /
+\                  Tags (length + padded data)                  \
+/                                                               /
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Note (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct TaggedStruct {
+	string Name<8>;
+	opaque Blob<16>;
+	string Tags<2>;
+	opaque Note<4>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o TaggedStruct) XDRSize() int {
+	return xdr.StringSize(o.Name) +
+		xdr.BytesSize(len(o.Blob)) +
+		4 + xdr.SizeOfSlice(o.Tags) +
+		xdr.BytesSize(len(o.Note))
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o TaggedStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o TaggedStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o TaggedStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Name); l > 8 {
+		return xdr.ElementSizeExceeded("Name", l, 8)
+	}
+	m.MarshalString(o.Name)
+	if l := len(o.Blob); l > 16 {
+		return xdr.ElementSizeExceeded("Blob", l, 16)
+	}
+	m.MarshalBytes(o.Blob)
+	if l := len(o.Tags); l > 2 {
+		return xdr.ElementSizeExceeded("Tags", l, 2)
+	}
+	m.MarshalUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		m.MarshalString(o.Tags[i])
+	}
+	if l := len(o.Note); l > 4 {
+		return xdr.ElementSizeExceeded("Note", l, 4)
+	}
+	m.MarshalBytes(o.Note)
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o TaggedStruct) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Name); l > 8 {
+		return xdr.ElementSizeExceeded("Name", l, 8)
+	}
+	e.EncodeString(o.Name)
+	if l := len(o.Blob); l > 16 {
+		return xdr.ElementSizeExceeded("Blob", l, 16)
+	}
+	e.EncodeBytes(o.Blob)
+	if l := len(o.Tags); l > 2 {
+		return xdr.ElementSizeExceeded("Tags", l, 2)
+	}
+	e.EncodeUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		e.EncodeString(o.Tags[i])
+	}
+	if l := len(o.Note); l > 4 {
+		return xdr.ElementSizeExceeded("Note", l, 4)
+	}
+	e.EncodeBytes(o.Note)
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *TaggedStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *TaggedStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *TaggedStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if _, err := xdr.CheckLength("Name", u.PeekUint32(), 8); err != nil {
+		return u.Fail(err)
+	}
+	o.Name = u.UnmarshalStringMax(8)
+	if _, err := xdr.CheckLength("Blob", u.PeekUint32(), 16); err != nil {
+		return u.Fail(err)
+	}
+	o.Blob = u.UnmarshalBytesMax(16)
+	_TagsSize := u.UnmarshalLength("Tags", 2)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _TagsSize == 0 {
+		o.Tags = nil
+	} else {
+		if !u.Require(_TagsSize, 4) {
+			return u.Error
+		}
+		if _TagsSize <= cap(o.Tags) {
+			for i := _TagsSize; i < len(o.Tags); i++ {
+				o.Tags[i] = ""
+			}
+			o.Tags = o.Tags[:_TagsSize]
+		} else {
+			_TagsCap := u.Capacity("TaggedStruct.Tags", _TagsSize)
+			if !u.Alloc(_TagsCap * int(unsafe.Sizeof(o.Tags[0]))) {
+				return u.Error
+			}
+			o.Tags = make([]string, _TagsSize, _TagsCap)
+		}
+		for i := range o.Tags {
+			o.Tags[i] = u.UnmarshalString()
+		}
+	}
+	if _, err := xdr.CheckLength("Note", u.PeekUint32(), 4); err != nil {
+		return u.Fail(err)
+	}
+	o.Note = u.UnmarshalBytesMax(4)
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o TaggedStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o TaggedStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *TaggedStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o TaggedStruct) Equal(p TaggedStruct) bool {
+	if o.Name != p.Name {
+		return false
+	}
+	if string(o.Blob) != string(p.Blob) {
+		return false
+	}
+	if len(o.Tags) != len(p.Tags) {
+		return false
+	}
+	for i := range o.Tags {
+		if o.Tags[i] != p.Tags[i] {
+			return false
+		}
+	}
+	if string(o.Note) != string(p.Note) {
+		return false
+	}
+	return true
+}
+
+/*
+
+MapStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                       Number of Labels                        |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\         Zero or more key/value pairs, sorted by key:          \
+\                  Key (length + padded data)                   \
+\                         string Value                          \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                       Number of Counts                        |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\         Zero or more key/value pairs, sorted by key:          \
+\                  Key (length + padded data)                   \
+\                          int64 Value                          \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                       Number of Others                        |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\         Zero or more key/value pairs, sorted by key:          \
+\                  Key (length + padded data)                   \
+\                     OtherStruct Structure                     \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Number of Statuses                       |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\         Zero or more key/value pairs, sorted by key:          \
+\                  Key (length + padded data)                   \
+\                        int32 Structure                        \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct MapStruct {
+	struct { string key<4>; string value<8>; } Labels<4>;
+	struct { string key<>; hyper value; } Counts<>;
+	struct { string key<2>; OtherStruct value; } Others<2>;
+	struct { string key<>; Status value; } Statuses<>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o MapStruct) XDRSize() int {
+	return 4 + xdr.SizeOfMap(o.Labels) +
+		4 + xdr.SizeOfMap(o.Counts) +
+		4 + xdr.SizeOfMap(o.Others) +
+		4 + xdr.SizeOfMap(o.Statuses)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o MapStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o MapStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o MapStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Labels); l > 4 {
+		return xdr.ElementSizeExceeded("Labels", l, 4)
+	}
+	m.MarshalUint32(uint32(len(o.Labels)))
+	for _, k := range xdr.SortedKeys(o.Labels) {
+		if l := len(k); l > 4 {
+			return xdr.ElementSizeExceeded("Labels key", l, 4)
+		}
+		m.MarshalString(k)
+		m.MarshalString(o.Labels[k])
+	}
+	m.MarshalUint32(uint32(len(o.Counts)))
+	for _, k := range xdr.SortedKeys(o.Counts) {
+		m.MarshalString(k)
+		m.MarshalUint64(uint64(o.Counts[k]))
+	}
+	if l := len(o.Others); l > 2 {
+		return xdr.ElementSizeExceeded("Others", l, 2)
+	}
+	m.MarshalUint32(uint32(len(o.Others)))
+	for _, k := range xdr.SortedKeys(o.Others) {
+		if l := len(k); l > 2 {
+			return xdr.ElementSizeExceeded("Others key", l, 2)
+		}
+		m.MarshalString(k)
+		if err := o.Others[k].MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	m.MarshalUint32(uint32(len(o.Statuses)))
+	for _, k := range xdr.SortedKeys(o.Statuses) {
+		m.MarshalString(k)
+		if err := o.Statuses[k].MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o MapStruct) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Labels); l > 4 {
+		return xdr.ElementSizeExceeded("Labels", l, 4)
+	}
+	e.EncodeUint32(uint32(len(o.Labels)))
+	for _, k := range xdr.SortedKeys(o.Labels) {
+		if l := len(k); l > 4 {
+			return xdr.ElementSizeExceeded("Labels key", l, 4)
+		}
+		e.EncodeString(k)
+		e.EncodeString(o.Labels[k])
+	}
+	e.EncodeUint32(uint32(len(o.Counts)))
+	for _, k := range xdr.SortedKeys(o.Counts) {
+		e.EncodeString(k)
+		e.EncodeUint64(uint64(o.Counts[k]))
+	}
+	if l := len(o.Others); l > 2 {
+		return xdr.ElementSizeExceeded("Others", l, 2)
+	}
+	e.EncodeUint32(uint32(len(o.Others)))
+	for _, k := range xdr.SortedKeys(o.Others) {
+		if l := len(k); l > 2 {
+			return xdr.ElementSizeExceeded("Others key", l, 2)
+		}
+		e.EncodeString(k)
+		if err := o.Others[k].EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	e.EncodeUint32(uint32(len(o.Statuses)))
+	for _, k := range xdr.SortedKeys(o.Statuses) {
+		e.EncodeString(k)
+		if err := o.Statuses[k].EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *MapStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *MapStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *MapStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	_LabelsSize := u.UnmarshalLength("Labels", 4)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _LabelsSize == 0 {
+		o.Labels = nil
+	} else {
+		if !u.Require(_LabelsSize, 4+4) {
+			return u.Error
+		}
+		_LabelsCap := u.Capacity("MapStruct.Labels", _LabelsSize)
+		if !u.Alloc(_LabelsCap * int(unsafe.Sizeof("")+unsafe.Sizeof(o.Labels[""]))) {
+			return u.Error
+		}
+		o.Labels = make(map[string]string, _LabelsCap)
+		var _LabelsPrev string
+		for i := 0; i < _LabelsSize; i++ {
+			if _, err := xdr.CheckLength("Labels key", u.PeekUint32(), 4); err != nil {
+				return u.Fail(err)
+			}
+			k := u.UnmarshalStringMax(4)
+			if u.Strict && i > 0 && k <= _LabelsPrev {
+				// Keys are marshalled sorted, so duplicates are rejected too.
+				return u.Fail(xdr.ErrMapKeyOrder)
+			}
+			_LabelsPrev = k
+			if _, err := xdr.CheckLength("Labels", u.PeekUint32(), 8); err != nil {
+				return u.Fail(err)
+			}
+			o.Labels[k] = u.UnmarshalStringMax(8)
+		}
+	}
+	_CountsSize := u.UnmarshalLength("Counts", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _CountsSize == 0 {
+		o.Counts = nil
+	} else {
+		if !u.Require(_CountsSize, 4+8) {
+			return u.Error
+		}
+		_CountsCap := u.Capacity("MapStruct.Counts", _CountsSize)
+		if !u.Alloc(_CountsCap * int(unsafe.Sizeof("")+unsafe.Sizeof(o.Counts[""]))) {
+			return u.Error
+		}
+		o.Counts = make(map[string]int64, _CountsCap)
+		var _CountsPrev string
+		for i := 0; i < _CountsSize; i++ {
+			k := u.UnmarshalString()
+			if u.Strict && i > 0 && k <= _CountsPrev {
+				// Keys are marshalled sorted, so duplicates are rejected too.
+				return u.Fail(xdr.ErrMapKeyOrder)
+			}
+			_CountsPrev = k
+			o.Counts[k] = int64(u.UnmarshalUint64())
+		}
+	}
+	_OthersSize := u.UnmarshalLength("Others", 2)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _OthersSize == 0 {
+		o.Others = nil
+	} else {
+		if !u.Require(_OthersSize, 4+8) {
+			return u.Error
+		}
+		_OthersCap := u.Capacity("MapStruct.Others", _OthersSize)
+		if !u.Alloc(_OthersCap * int(unsafe.Sizeof("")+unsafe.Sizeof(o.Others[""]))) {
+			return u.Error
+		}
+		o.Others = make(map[string]OtherStruct, _OthersCap)
+		var _OthersPrev string
+		for i := 0; i < _OthersSize; i++ {
+			if _, err := xdr.CheckLength("Others key", u.PeekUint32(), 2); err != nil {
+				return u.Fail(err)
+			}
+			k := u.UnmarshalStringMax(2)
+			if u.Strict && i > 0 && k <= _OthersPrev {
+				// Keys are marshalled sorted, so duplicates are rejected too.
+				return u.Fail(xdr.ErrMapKeyOrder)
+			}
+			_OthersPrev = k
+			var v OtherStruct
+			if err := v.UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
+			o.Others[k] = v
+		}
+	}
+	_StatusesSize := u.UnmarshalLength("Statuses", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _StatusesSize == 0 {
+		o.Statuses = nil
+	} else {
+		if !u.Require(_StatusesSize, 4+4) {
+			return u.Error
+		}
+		_StatusesCap := u.Capacity("MapStruct.Statuses", _StatusesSize)
+		if !u.Alloc(_StatusesCap * int(unsafe.Sizeof("")+unsafe.Sizeof(o.Statuses[""]))) {
+			return u.Error
+		}
+		o.Statuses = make(map[string]Status, _StatusesCap)
+		var _StatusesPrev string
+		for i := 0; i < _StatusesSize; i++ {
+			k := u.UnmarshalString()
+			if u.Strict && i > 0 && k <= _StatusesPrev {
+				// Keys are marshalled sorted, so duplicates are rejected too.
+				return u.Fail(xdr.ErrMapKeyOrder)
+			}
+			_StatusesPrev = k
+			var v Status
+			if err := v.UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
+			o.Statuses[k] = v
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o MapStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o MapStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *MapStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o MapStruct) Equal(p MapStruct) bool {
+	if len(o.Labels) != len(p.Labels) {
+		return false
+	}
+	for k, v := range o.Labels {
+		if w, ok := p.Labels[k]; !ok || v != w {
+			return false
+		}
+	}
+	if len(o.Counts) != len(p.Counts) {
+		return false
+	}
+	for k, v := range o.Counts {
+		if w, ok := p.Counts[k]; !ok || v != w {
+			return false
+		}
+	}
+	if len(o.Others) != len(p.Others) {
+		return false
+	}
+	for k, v := range o.Others {
+		if w, ok := p.Others[k]; !ok || !v.Equal(w) {
+			return false
+		}
+	}
+	if len(o.Statuses) != len(p.Statuses) {
+		return false
+	}
+	for k, v := range o.Statuses {
+		if w, ok := p.Statuses[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+FloatStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                              F32                              |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                                                               |
++                         F64 (64 bits)                         +
+|                                                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                         Number of Fs                          |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+|                                                               |
++                         Fs (64 bits)                          +
+|                                                               |
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct FloatStruct {
+	float F32;
+	double F64;
+	double Fs<>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o FloatStruct) XDRSize() int {
+	return 4 + 8 +
+		4 + len(o.Fs)*8
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o FloatStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o FloatStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o FloatStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalFloat32(o.F32)
+	m.MarshalFloat64(o.F64)
+	m.MarshalUint32(uint32(len(o.Fs)))
+	for i := range o.Fs {
+		m.MarshalFloat64(o.Fs[i])
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o FloatStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeFloat32(o.F32)
+	e.EncodeFloat64(o.F64)
+	e.EncodeUint32(uint32(len(o.Fs)))
+	for i := range o.Fs {
+		e.EncodeFloat64(o.Fs[i])
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *FloatStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *FloatStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *FloatStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	o.F32 = u.UnmarshalFloat32()
+	o.F64 = u.UnmarshalFloat64()
+	_FsSize := u.UnmarshalLength("Fs", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _FsSize == 0 {
+		o.Fs = nil
+	} else {
+		if !u.Require(_FsSize, 8) {
+			return u.Error
+		}
+		if _FsSize <= cap(o.Fs) {
+			o.Fs = o.Fs[:_FsSize]
+		} else {
+			_FsCap := u.Capacity("FloatStruct.Fs", _FsSize)
+			if !u.Alloc(_FsCap * int(unsafe.Sizeof(o.Fs[0]))) {
+				return u.Error
+			}
+			o.Fs = make([]float64, _FsSize, _FsCap)
+		}
+		for i := range o.Fs {
+			o.Fs[i] = u.UnmarshalFloat64()
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o FloatStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o FloatStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *FloatStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+// Floats are compared by their bits, so NaN equals itself
+// while 0 and -0 differ.
+func (o FloatStruct) Equal(p FloatStruct) bool {
+	if math.Float32bits(o.F32) != math.Float32bits(p.F32) {
+		return false
+	}
+	if math.Float64bits(o.F64) != math.Float64bits(p.F64) {
+		return false
+	}
+	if len(o.Fs) != len(p.Fs) {
+		return false
+	}
+	for i := range o.Fs {
+		if math.Float64bits(o.Fs[i]) != math.Float64bits(p.Fs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+Chain Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                               V                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                     Has Next (V=0 or 1)                     |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                        Chain Structure                        \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct Chain {
+	unsigned int V;
+	Chain *Next;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o Chain) XDRSize() int {
+	s := 4 + 4
+	if o.Next != nil {
+		s += o.Next.XDRSize()
+	}
+	return s
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Chain) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Chain) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o Chain) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(o.V)
+	m.MarshalBool(o.Next != nil)
+	if o.Next != nil {
+		if err := o.Next.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o Chain) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint32(o.V)
+	e.EncodeBool(o.Next != nil)
+	if o.Next != nil {
+		if err := o.Next.EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *Chain) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *Chain) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *Chain) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	o.V = u.UnmarshalUint32()
+	if u.UnmarshalBool() {
+		if o.Next == nil {
+			o.Next = new(Chain)
+		}
+		if err := o.Next.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+	} else {
+		o.Next = nil
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Chain) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Chain) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Chain) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o Chain) Equal(p Chain) bool {
+	if o.V != p.V {
+		return false
+	}
+	if (o.Next == nil) != (p.Next == nil) || o.Next != nil && !o.Next.Equal(*p.Next) {
+		return false
+	}
+	return true
+}
+
+/*
+
+HashStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                   Hash (32 bytes + padding)                   \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                   Short (5 bytes + padding)                   \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                               N                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct HashStruct {
+	opaque Hash[32];
+	opaque Short[5];
+	unsigned int N;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o HashStruct) XDRSize() int {
+	return 32 + 8 + 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o HashStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o HashStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o HashStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalFixedOpaque(o.Hash[:])
+	m.MarshalFixedOpaque(o.Short[:])
+	m.MarshalUint32(o.N)
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o HashStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeFixedOpaque(o.Hash[:])
+	e.EncodeFixedOpaque(o.Short[:])
+	e.EncodeUint32(o.N)
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *HashStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *HashStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *HashStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	copy(o.Hash[:], u.UnmarshalFixedOpaque(32))
+	copy(o.Short[:], u.UnmarshalFixedOpaque(5))
+	o.N = u.UnmarshalUint32()
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o HashStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o HashStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *HashStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o HashStruct) Equal(p HashStruct) bool {
+	if o.Hash != p.Hash {
+		return false
+	}
+	if o.Short != p.Short {
+		return false
+	}
+	if o.N != p.N {
+		return false
+	}
+	return true
+}
+
+/*
+
+NamedStruct Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                                                               |
++                         ID (64 bits)                          +
+|                                                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                                                               |
++                         At (64 bits)                          +
+|                                                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                 24 zero bits                  |      Lvl      |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Name (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Data (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                         Number of IDs                         |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+|                                                               |
++                         IDs (64 bits)                         +
+|                                                               |
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                       Number of Labels                        |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+/                                                               /
+\                 Labels (length + padded data)                 \
+/                                                               /
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                     Has Prev (V=0 or 1)                     |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                                                               |
++                        Prev (64 bits)                         +
+|                                                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct NamedStruct {
+	unsigned hyper ID;
+	hyper At;
+	unsigned int Lvl;
+	string Name<8>;
+	opaque Data<>;
+	unsigned hyper IDs<>;
+	string Labels<4>;
+	unsigned hyper *Prev;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o NamedStruct) XDRSize() int {
+	s := 8 + 8 + 4 +
+		xdr.StringSize(string(o.Name)) +
+		xdr.BytesSize(len(o.Data)) +
+		4 + len(o.IDs)*8 +
+		4 + xdr.SizeOfSlice(o.Labels) + 4
+	if o.Prev != nil {
+		s += 8
+	}
+	return s
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o NamedStruct) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o NamedStruct) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o NamedStruct) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint64(uint64(o.ID))
+	m.MarshalUint64(uint64(o.At))
+	m.MarshalUint8(uint8(o.Lvl))
+	if l := len(o.Name); l > 8 {
+		return xdr.ElementSizeExceeded("Name", l, 8)
+	}
+	m.MarshalString(string(o.Name))
+	m.MarshalBytes([]byte(o.Data))
+	m.MarshalUint32(uint32(len(o.IDs)))
+	for i := range o.IDs {
+		m.MarshalUint64(uint64(o.IDs[i]))
+	}
+	if l := len(o.Labels); l > 4 {
+		return xdr.ElementSizeExceeded("Labels", l, 4)
+	}
+	m.MarshalUint32(uint32(len(o.Labels)))
+	for i := range o.Labels {
+		m.MarshalString(string(o.Labels[i]))
+	}
+	m.MarshalBool(o.Prev != nil)
+	if o.Prev != nil {
+		m.MarshalUint64(uint64(*o.Prev))
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o NamedStruct) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint64(uint64(o.ID))
+	e.EncodeUint64(uint64(o.At))
+	e.EncodeUint8(uint8(o.Lvl))
+	if l := len(o.Name); l > 8 {
+		return xdr.ElementSizeExceeded("Name", l, 8)
+	}
+	e.EncodeString(string(o.Name))
+	e.EncodeBytes([]byte(o.Data))
+	e.EncodeUint32(uint32(len(o.IDs)))
+	for i := range o.IDs {
+		e.EncodeUint64(uint64(o.IDs[i]))
+	}
+	if l := len(o.Labels); l > 4 {
+		return xdr.ElementSizeExceeded("Labels", l, 4)
+	}
+	e.EncodeUint32(uint32(len(o.Labels)))
+	for i := range o.Labels {
+		e.EncodeString(string(o.Labels[i]))
+	}
+	e.EncodeBool(o.Prev != nil)
+	if o.Prev != nil {
+		e.EncodeUint64(uint64(*o.Prev))
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *NamedStruct) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *NamedStruct) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *NamedStruct) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	o.ID = NodeID(u.UnmarshalUint64())
+	o.At = Timestamp(u.UnmarshalUint64())
+	o.Lvl = Level(u.UnmarshalUint8())
+	if _, err := xdr.CheckLength("Name", u.PeekUint32(), 8); err != nil {
+		return u.Fail(err)
+	}
+	o.Name = Label(u.UnmarshalStringMax(8))
+	o.Data = Blob(u.UnmarshalBytes())
+	_IDsSize := u.UnmarshalLength("IDs", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _IDsSize == 0 {
+		o.IDs = nil
+	} else {
+		if !u.Require(_IDsSize, 8) {
+			return u.Error
+		}
+		if _IDsSize <= cap(o.IDs) {
+			o.IDs = o.IDs[:_IDsSize]
+		} else {
+			_IDsCap := u.Capacity("NamedStruct.IDs", _IDsSize)
+			if !u.Alloc(_IDsCap * int(unsafe.Sizeof(o.IDs[0]))) {
+				return u.Error
+			}
+			o.IDs = make([]NodeID, _IDsSize, _IDsCap)
+		}
+		for i := range o.IDs {
+			o.IDs[i] = NodeID(u.UnmarshalUint64())
+		}
+	}
+	_LabelsSize := u.UnmarshalLength("Labels", 4)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _LabelsSize == 0 {
+		o.Labels = nil
+	} else {
+		if !u.Require(_LabelsSize, 4) {
+			return u.Error
+		}
+		if _LabelsSize <= cap(o.Labels) {
+			o.Labels = o.Labels[:_LabelsSize]
+		} else {
+			_LabelsCap := u.Capacity("NamedStruct.Labels", _LabelsSize)
+			if !u.Alloc(_LabelsCap * int(unsafe.Sizeof(o.Labels[0]))) {
+				return u.Error
+			}
+			o.Labels = make([]Label, _LabelsSize, _LabelsCap)
+		}
+		for i := range o.Labels {
+			if _, err := xdr.CheckLength("Labels", u.PeekUint32(), 8); err != nil {
+				return u.Fail(err)
+			}
+			o.Labels[i] = Label(u.UnmarshalStringMax(8))
+		}
+	}
+	if u.UnmarshalBool() {
+		if o.Prev == nil {
+			o.Prev = new(NodeID)
+		}
+		*o.Prev = NodeID(u.UnmarshalUint64())
+	} else {
+		o.Prev = nil
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o NamedStruct) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o NamedStruct) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *NamedStruct) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o NamedStruct) Equal(p NamedStruct) bool {
+	if o.ID != p.ID {
+		return false
+	}
+	if o.At != p.At {
+		return false
+	}
+	if o.Lvl != p.Lvl {
+		return false
+	}
+	if o.Name != p.Name {
+		return false
+	}
+	if string(o.Data) != string(p.Data) {
+		return false
+	}
+	if len(o.IDs) != len(p.IDs) {
+		return false
+	}
+	for i := range o.IDs {
+		if o.IDs[i] != p.IDs[i] {
+			return false
+		}
+	}
+	if len(o.Labels) != len(p.Labels) {
+		return false
+	}
+	for i := range o.Labels {
+		if o.Labels[i] != p.Labels[i] {
+			return false
+		}
+	}
+	if (o.Prev == nil) != (p.Prev == nil) || o.Prev != nil && *o.Prev != *p.Prev {
+		return false
+	}
+	return true
+}
+
+/*
+
+RecordV1 Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Length of Record V1                      |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                              ID                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Name (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+// Versioned: encoded as variable-length opaque data.
+struct RecordV1 {
+	unsigned int ID;
+	string Name<>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o RecordV1) XDRSize() int {
+	return 4 + 4 +
+		xdr.StringSize(o.Name)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o RecordV1) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o RecordV1) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o RecordV1) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(uint32(o.XDRSize() - 4))
+	m.MarshalUint32(o.ID)
+	m.MarshalString(o.Name)
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o RecordV1) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint32(uint32(o.XDRSize() - 4))
+	e.EncodeUint32(o.ID)
+	e.EncodeString(o.Name)
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *RecordV1) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *RecordV1) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// The struct is versioned: data following the known fields, appended by a
+// newer version, is skipped, and fields missing from the end of the data,
+// written by an older version, are left zero.
+func (o *RecordV1) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := o.unmarshalXDRFields(u.UnmarshalNested()); err != nil {
+		u.Fail(err)
+		return err
+	}
+	return nil
+}
+
+// unmarshalXDRFields unmarshals the fields of the struct from an
+// Unmarshaller over its encoding, stopping at the end of the data.
+func (o *RecordV1) unmarshalXDRFields(u *xdr.Unmarshaller) error {
+	*o = RecordV1{}
+	if u.Remaining() == 0 {
+		return u.Error
+	}
+	o.ID = u.UnmarshalUint32()
+	if u.Remaining() == 0 {
+		return u.Error
+	}
+	o.Name = u.UnmarshalString()
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o RecordV1) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o RecordV1) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *RecordV1) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o RecordV1) Equal(p RecordV1) bool {
+	if o.ID != p.ID {
+		return false
+	}
+	if o.Name != p.Name {
+		return false
+	}
+	return true
+}
+
+/*
+
+RecordV2 Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                      Length of Record V2                      |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                              ID                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Name (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Number of Tags                         |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+/                                                               /
+\                  Tags (length + padded data)                  \
+/                                                               /
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                    Has Owner (V=0 or 1)                     |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                     OtherStruct Structure                     \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+// Versioned: encoded as variable-length opaque data.
+struct RecordV2 {
+	unsigned int ID;
+	string Name<>;
+	string Tags<>;
+	OtherStruct *Owner;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o RecordV2) XDRSize() int {
+	s := 4 + 4 +
+		xdr.StringSize(o.Name) +
+		4 + xdr.SizeOfSlice(o.Tags) + 4
+	if o.Owner != nil {
+		s += o.Owner.XDRSize()
+	}
+	return s
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o RecordV2) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o RecordV2) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o RecordV2) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(uint32(o.XDRSize() - 4))
+	m.MarshalUint32(o.ID)
+	m.MarshalString(o.Name)
+	m.MarshalUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		m.MarshalString(o.Tags[i])
+	}
+	m.MarshalBool(o.Owner != nil)
+	if o.Owner != nil {
+		if err := o.Owner.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o RecordV2) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeUint32(uint32(o.XDRSize() - 4))
+	e.EncodeUint32(o.ID)
+	e.EncodeString(o.Name)
+	e.EncodeUint32(uint32(len(o.Tags)))
+	for i := range o.Tags {
+		e.EncodeString(o.Tags[i])
+	}
+	e.EncodeBool(o.Owner != nil)
+	if o.Owner != nil {
+		if err := o.Owner.EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *RecordV2) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *RecordV2) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// The struct is versioned: data following the known fields, appended by a
+// newer version, is skipped, and fields missing from the end of the data,
+// written by an older version, are left zero.
+func (o *RecordV2) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := o.unmarshalXDRFields(u.UnmarshalNested()); err != nil {
+		u.Fail(err)
+		return err
+	}
+	return nil
+}
+
+// unmarshalXDRFields unmarshals the fields of the struct from an
+// Unmarshaller over its encoding, stopping at the end of the data.
+func (o *RecordV2) unmarshalXDRFields(u *xdr.Unmarshaller) error {
+	*o = RecordV2{}
+	if u.Remaining() == 0 {
+		return u.Error
+	}
+	o.ID = u.UnmarshalUint32()
+	if u.Remaining() == 0 {
+		return u.Error
+	}
+	o.Name = u.UnmarshalString()
+	if u.Remaining() == 0 {
+		return u.Error
+	}
+	_TagsSize := u.UnmarshalLength("Tags", 0)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _TagsSize == 0 {
+		o.Tags = nil
+	} else {
+		if !u.Require(_TagsSize, 4) {
+			return u.Error
+		}
+		if _TagsSize <= cap(o.Tags) {
+			for i := _TagsSize; i < len(o.Tags); i++ {
+				o.Tags[i] = ""
+			}
+			o.Tags = o.Tags[:_TagsSize]
+		} else {
+			_TagsCap := u.Capacity("RecordV2.Tags", _TagsSize)
+			if !u.Alloc(_TagsCap * int(unsafe.Sizeof(o.Tags[0]))) {
+				return u.Error
+			}
+			o.Tags = make([]string, _TagsSize, _TagsCap)
+		}
+		for i := range o.Tags {
+			o.Tags[i] = u.UnmarshalString()
+		}
+	}
+	if u.Remaining() == 0 {
+		return u.Error
+	}
+	if u.UnmarshalBool() {
+		if o.Owner == nil {
+			o.Owner = new(OtherStruct)
+		}
+		if err := o.Owner.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+	} else {
+		o.Owner = nil
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o RecordV2) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o RecordV2) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *RecordV2) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o RecordV2) Equal(p RecordV2) bool {
+	if o.ID != p.ID {
+		return false
+	}
+	if o.Name != p.Name {
+		return false
+	}
+	if len(o.Tags) != len(p.Tags) {
+		return false
+	}
+	for i := range o.Tags {
+		if o.Tags[i] != p.Tags[i] {
+			return false
+		}
+	}
+	if (o.Owner == nil) != (p.Owner == nil) || o.Owner != nil && !o.Owner.Equal(*p.Owner) {
+		return false
+	}
+	return true
+}
+
+/*
+
+Envelope Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                      RecordV1 Structure                       \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                             After                             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct Envelope {
+	RecordV1 Record;
+	unsigned int After;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o Envelope) XDRSize() int {
+	return o.Record.XDRSize() + 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o Envelope) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o Envelope) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o Envelope) MarshalXDRInto(m *xdr.Marshaller) error {
+	if err := o.Record.MarshalXDRInto(m); err != nil {
+		return err
+	}
+	m.MarshalUint32(o.After)
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o Envelope) EncodeXDR(e *xdr.Encoder) error {
+	if err := o.Record.EncodeXDR(e); err != nil {
+		return err
+	}
+	e.EncodeUint32(o.After)
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *Envelope) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *Envelope) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *Envelope) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := (&o.Record).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	o.After = u.UnmarshalUint32()
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o Envelope) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o Envelope) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *Envelope) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o Envelope) Equal(p Envelope) bool {
+	if !o.Record.Equal(p.Record) {
+		return false
+	}
+	if o.After != p.After {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,238 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"bufio"
+	"hash"
+	"io"
+	"math"
+)
+
+// Encoder writes XDR encoded values to an io.Writer. Writes are buffered
+// internally, so Flush must be called once all values have been encoded.
+// Once an error has occurred, all further Encode... calls return that same
+// error.
+type Encoder struct {
+	w   *bufio.Writer
+	h   hash.Hash
+	buf [8]byte
+	err error
+	n   int64
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.w.Flush()
+	return e.err
+}
+
+// BytesWritten returns the number of bytes encoded so far, including size
+// prefixes and padding. Bytes still buffered, awaiting a Flush, are counted.
+func (e *Encoder) BytesWritten() int64 {
+	return e.n
+}
+
+// SetHash makes the Encoder write all data it encodes from now on to h as
+// well, so that a checksum over it is computed in the same pass; for CRC32C,
+// h would be crc32.New(crc32.MakeTable(crc32.Castagnoli)). A nil h stops
+// hashing.
+func (e *Encoder) SetHash(h hash.Hash) {
+	e.h = h
+}
+
+// Sum appends the current sum of the hash set by SetHash to b and returns
+// the result.
+func (e *Encoder) Sum(b []byte) []byte {
+	return e.h.Sum(b)
+}
+
+// EncodeSum writes the sum of the hash set by SetHash to the stream, as
+// fixed-length opaque data, and resets the hash, so that the next checksum
+// covers the data encoded after this one. See Decoder.DecodeSum for the
+// decoding side.
+func (e *Encoder) EncodeSum() error {
+	if e.h == nil {
+		panic("xdr: Encoder.EncodeSum without a hash")
+	}
+
+	h := e.h
+	e.h = nil
+	e.EncodeFixedOpaque(h.Sum(e.buf[:0]))
+	h.Reset()
+	e.h = h
+	return e.err
+}
+
+// Err returns the error that stopped encoding, if any.
+func (e *Encoder) Err() error {
+	return e.err
+}
+
+// EncodeRaw writes the raw bytes to the stream, without a size prefix or
+// padding.
+func (e *Encoder) EncodeRaw(bs []byte) error {
+	e.write(bs)
+	return e.err
+}
+
+// EncodeFixedOpaque writes the bytes to the stream, without a size prefix
+// but with correct padding.
+func (e *Encoder) EncodeFixedOpaque(bs []byte) error {
+	e.write(bs)
+	e.write(padBytes[:Padding(len(bs))])
+	return e.err
+}
+
+// EncodeString writes the string to the stream, with a size prefix and
+// correct padding.
+func (e *Encoder) EncodeString(s string) error {
+	e.EncodeUint32(uint32(len(s)))
+	if e.err == nil {
+		var n int
+		n, e.err = e.w.WriteString(s)
+		e.n += int64(n)
+		if e.h != nil {
+			io.WriteString(e.h, s[:n])
+		}
+	}
+	e.write(padBytes[:Padding(len(s))])
+	return e.err
+}
+
+// EncodeBytes writes the bytes to the stream, with a size prefix and correct
+// padding.
+func (e *Encoder) EncodeBytes(bs []byte) error {
+	e.EncodeUint32(uint32(len(bs)))
+	e.write(bs)
+	e.write(padBytes[:Padding(len(bs))])
+	return e.err
+}
+
+// EncodeBytesFrom writes length bytes read from r to the stream, with a size
+// prefix and correct padding, as EncodeBytes would, but without holding them
+// in memory. If r ends before length bytes, the error is
+// io.ErrUnexpectedEOF; as with errors reading r, the value is left
+// incomplete and the Encoder keeps returning the error. Lengths above
+// math.MaxInt32, which DecodeBytes would reject, fail with an
+// ElementSizeError before anything is written.
+func (e *Encoder) EncodeBytesFrom(r io.Reader, length int) error {
+	if e.err != nil {
+		return e.err
+	}
+	if err := checkEncodedLength("bytes field", length); err != nil {
+		e.err = err
+		return e.err
+	}
+
+	e.EncodeUint32(uint32(length))
+	if _, err := io.CopyN(encoderWriter{e}, r, int64(length)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if e.err == nil {
+			e.err = err
+		}
+		return e.err
+	}
+	e.write(padBytes[:Padding(length)])
+	return e.err
+}
+
+// EncodeBool writes the bool to the stream, as an uint32.
+func (e *Encoder) EncodeBool(v bool) error {
+	if v {
+		return e.EncodeUint8(1)
+	}
+	return e.EncodeUint8(0)
+}
+
+// EncodeUint8 writes the uint8 to the stream, as an uint32.
+func (e *Encoder) EncodeUint8(v uint8) error {
+	return e.EncodeUint32(uint32(v))
+}
+
+// EncodeUint16 writes the uint16 to the stream, as an uint32.
+func (e *Encoder) EncodeUint16(v uint16) error {
+	return e.EncodeUint32(uint32(v))
+}
+
+// EncodeUint32 writes the uint32 to the stream.
+func (e *Encoder) EncodeUint32(v uint32) error {
+	PutUint32BE(e.buf[:], v)
+	e.write(e.buf[:4])
+	return e.err
+}
+
+// EncodeUint64 writes the uint64 to the stream.
+func (e *Encoder) EncodeUint64(v uint64) error {
+	PutUint64BE(e.buf[:], v)
+	e.write(e.buf[:8])
+	return e.err
+}
+
+// EncodeInt8 writes the int8 to the stream, as an uint32.
+func (e *Encoder) EncodeInt8(v int8) error {
+	return e.EncodeUint8(uint8(v))
+}
+
+// EncodeInt16 writes the int16 to the stream, as an uint32.
+func (e *Encoder) EncodeInt16(v int16) error {
+	return e.EncodeUint16(uint16(v))
+}
+
+// EncodeInt32 writes the int32 to the stream.
+func (e *Encoder) EncodeInt32(v int32) error {
+	return e.EncodeUint32(uint32(v))
+}
+
+// EncodeInt64 writes the int64 to the stream.
+func (e *Encoder) EncodeInt64(v int64) error {
+	return e.EncodeUint64(uint64(v))
+}
+
+// EncodeFloat32 writes the float32 to the stream, as its IEEE 754 bit
+// representation.
+func (e *Encoder) EncodeFloat32(v float32) error {
+	return e.EncodeUint32(math.Float32bits(v))
+}
+
+// EncodeFloat64 writes the float64 to the stream, as its IEEE 754 bit
+// representation.
+func (e *Encoder) EncodeFloat64(v float64) error {
+	return e.EncodeUint64(math.Float64bits(v))
+}
+
+func (e *Encoder) write(p []byte) {
+	if e.err != nil {
+		return
+	}
+	var n int
+	n, e.err = e.w.Write(p)
+	e.n += int64(n)
+	if e.h != nil {
+		e.h.Write(p[:n])
+	}
+}
+
+// encoderWriter writes to the stream of an Encoder, so that data copied into
+// it is counted and hashed like encoded values.
+type encoderWriter struct {
+	e *Encoder
+}
+
+func (w encoderWriter) Write(p []byte) (int, error) {
+	n := w.e.n
+	w.e.write(p)
+	return int(w.e.n - n), w.e.err
+}
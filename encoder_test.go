@@ -0,0 +1,227 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"dario.cat/xdr"
+)
+
+func TestEncoder(t *testing.T) {
+	var s TestStruct
+	s.B = true
+	s.I = -3
+	s.I8 = -4
+	s.UI16 = 5
+	s.I32 = -6
+	s.UI64 = 7
+	s.BS = []byte{1, 2, 3}
+	s.S = "hello"
+	s.C[0] = 8
+
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	e.EncodeBool(s.B)
+	e.EncodeUint64(uint64(s.I))
+	e.EncodeInt8(s.I8)
+	e.EncodeUint8(s.UI8)
+	e.EncodeInt16(s.I16)
+	e.EncodeUint16(s.UI16)
+	e.EncodeInt32(s.I32)
+	e.EncodeUint32(s.UI32)
+	e.EncodeInt64(s.I64)
+	e.EncodeUint64(s.UI64)
+	e.EncodeBytes(s.BS)
+	e.EncodeString(s.S)
+	e.EncodeFixedOpaque(s.C[:])
+	for i := 0; i < 4; i++ {
+		// SS, OS.F1, OS.F2 and OSs
+		e.EncodeUint32(0)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	if expected := s.MustMarshalXDR(); !bytes.Equal(buf.Bytes(), expected) {
+		t.Fatalf("Expected %x, got %x", expected, buf.Bytes())
+	}
+}
+
+type failingWriter struct{}
+
+var errWrite = errors.New("write failed")
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestEncoderError(t *testing.T) {
+	e := xdr.NewEncoder(failingWriter{})
+	e.EncodeUint32(1)
+	if err := e.Flush(); err != errWrite {
+		t.Fatal("Expected write error, got", err)
+	}
+	if err := e.EncodeString("latched"); err != errWrite {
+		t.Fatal("Expected latched write error, got", err)
+	}
+}
+
+func TestMarshalXDRTo(t *testing.T) {
+	fn := func(t0 TestStruct) bool {
+		var buf bytes.Buffer
+		if err := t0.MarshalXDRTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return bytes.Equal(buf.Bytes(), t0.MustMarshalXDR())
+	}
+	if err := quick.Check(fn, nil); err != nil {
+		t.Error(err)
+	}
+
+	for _, v := range []interface {
+		xdr.Sizer
+		MarshalXDR() ([]byte, error)
+		MarshalXDRTo(io.Writer) error
+	}{
+		EnumStruct{S: StatusOK, Ss: []Status{StatusFailed}},
+		Result{Code: StatusFailed, Value: &Failure{Reason: "streamed"}},
+		OptionalStruct{S: new(string)},
+		HashStruct{N: 1},
+		EmptyStruct{},
+	} {
+		var buf bytes.Buffer
+		if err := v.MarshalXDRTo(&buf); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if bs, _ := v.MarshalXDR(); !bytes.Equal(buf.Bytes(), bs) {
+			t.Errorf("Streamed %x != marshalled %x", buf.Bytes(), bs)
+		}
+	}
+
+	if err := (TaggedStruct{Name: "too long name"}).MarshalXDRTo(io.Discard); err == nil {
+		t.Error("Expected error for oversized Name")
+	}
+}
+
+func TestByteCounters(t *testing.T) {
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	e.EncodeUint32(1)
+	e.EncodeString("hello")
+	e.EncodeBytes([]byte{1})
+	if n := e.BytesWritten(); n != 4+12+8 {
+		t.Errorf("Expected %d bytes written, got %d", 4+12+8, n)
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if n := e.BytesWritten(); n != int64(buf.Len()) {
+		t.Errorf("Expected %d bytes written, got %d", buf.Len(), n)
+	}
+
+	d := xdr.NewDecoder(&buf)
+	d.DecodeUint32()
+	if n := d.BytesRead(); n != 4 {
+		t.Errorf("Expected 4 bytes read, got %d", n)
+	}
+	d.DecodeString()
+	d.DecodeBytes()
+	if n := d.BytesRead(); n != 4+12+8 {
+		t.Errorf("Expected %d bytes read, got %d", 4+12+8, n)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	table := crc32.MakeTable(crc32.Castagnoli)
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	e.SetHash(crc32.New(table))
+	for _, s := range []string{"hello", "world"} {
+		e.EncodeString(s)
+		e.EncodeUint32(42)
+		e.EncodeSum()
+	}
+	if err := e.Flush(); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// Each checksum covers the data since the previous one.
+	bs := buf.Bytes()
+	if sum := crc32.Checksum(bs[:16], table); sum != binary.BigEndian.Uint32(bs[16:]) {
+		t.Errorf("Expected checksum %08x, got %x", sum, bs[16:20])
+	}
+
+	d := xdr.NewDecoder(bytes.NewReader(bs))
+	d.SetHash(crc32.New(table))
+	for i := 0; i < 2; i++ {
+		d.DecodeString()
+		d.DecodeUint32()
+		if err := d.DecodeSum(); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+	}
+
+	bs[5] ^= 1
+	d = xdr.NewDecoder(bytes.NewReader(bs))
+	d.SetHash(crc32.New(table))
+	d.DecodeString()
+	d.DecodeUint32()
+	if err := d.DecodeSum(); err != xdr.ErrChecksum {
+		t.Fatal("Expected ErrChecksum, got", err)
+	}
+	if _, err := d.DecodeString(); err != xdr.ErrChecksum {
+		t.Fatal("Expected ErrChecksum to latch, got", err)
+	}
+}
+
+func TestEncodeBytesFrom(t *testing.T) {
+	var buf bytes.Buffer
+	e := xdr.NewEncoder(&buf)
+	if err := e.EncodeBytesFrom(strings.NewReader("hello, world"), 5); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	e.Flush()
+
+	var expected bytes.Buffer
+	ee := xdr.NewEncoder(&expected)
+	ee.EncodeBytes([]byte("hello"))
+	ee.Flush()
+	if !bytes.Equal(buf.Bytes(), expected.Bytes()) {
+		t.Errorf("Expected %x, got %x", expected.Bytes(), buf.Bytes())
+	}
+	if e.BytesWritten() != 12 {
+		t.Error("Expected 12 bytes written, got", e.BytesWritten())
+	}
+
+	e = xdr.NewEncoder(io.Discard)
+	if err := e.EncodeBytesFrom(strings.NewReader("abc"), 4); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if err := e.EncodeUint32(1); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected the error to latch, got", err)
+	}
+
+	// Lengths the decoding side would reject are not written, on 32 bit
+	// builds, where the length wraps to a negative int, as on 64 bit ones.
+	l := math.MaxInt32
+	l++
+	e = xdr.NewEncoder(io.Discard)
+	var se *xdr.ElementSizeError
+	if err := e.EncodeBytesFrom(strings.NewReader("abc"), l); !errors.As(err, &se) || se.Max != math.MaxInt32 {
+		t.Fatal("Expected an ElementSizeError, got", err)
+	}
+	if e.BytesWritten() != 0 {
+		t.Error("Expected nothing written, got", e.BytesWritten())
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import "math"
+
+// float32ToFloat16 converts f to IEEE 754 binary16, rounding to nearest,
+// ties to even. Values too large for binary16 become infinities and values
+// too small become zeros of the same sign; NaNs stay NaNs.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			// Keep the NaN quiet and the top of its payload.
+			return sign | 0x7e00 | uint16(mant>>13)
+		}
+		return sign | 0x7c00
+	}
+
+	e := exp - 127 + 15
+	switch {
+	case e >= 0x1f:
+		return sign | 0x7c00
+	case e < -10:
+		// Less than half the smallest subnormal.
+		return sign
+	case e <= 0:
+		// Subnormal, with the implicit leading bit made explicit.
+		m := mant | 0x800000
+		shift := uint(14 - e)
+		h := m >> shift
+		rem, halfway := m&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > halfway || rem == halfway && h&1 == 1 {
+			// May carry into the smallest normal, which is still correct.
+			h++
+		}
+		return sign | uint16(h)
+	}
+
+	h := uint32(e)<<10 | mant>>13
+	rem := mant & 0x1fff
+	if rem > 0x1000 || rem == 0x1000 && h&1 == 1 {
+		// May carry into the exponent, up to infinity.
+		h++
+	}
+	return sign | uint16(h)
+}
+
+// float16ToFloat32 converts the IEEE 754 binary16 value h to a float32,
+// which represents it exactly.
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := int(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// Normalize the subnormal.
+		e := -14
+		for mant&0x400 == 0 {
+			mant <<= 1
+			e--
+		}
+		mant &= 0x3ff
+		return math.Float32frombits(sign | uint32(e+127)<<23 | mant<<13)
+	}
+
+	return math.Float32frombits(sign | uint32(exp-15+127)<<23 | mant<<13)
+}
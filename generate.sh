@@ -1,4 +1,6 @@
 #!/bin/sh
 
-go run cmd/genxdr/main.go -- bench_test.go > bench_xdr_test.go
-go run cmd/genxdr/main.go -- encdec_test.go > encdec_xdr_test.go
+go run ./cmd/genxdr -o bench_xdr_test.go -- bench_test.go
+go run ./cmd/genxdr -o encdec_xdr_test.go -tests roundtrip_xdr_test.go -- encdec_test.go
+go run ./cmd/genxdr -package xdr_test -o idl_xdr_test.go -- idl_test.x
+go run ./cmd/genxdr -panic -o panic_xdr_test.go -- panic_test.go
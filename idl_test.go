@@ -0,0 +1,90 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dario.cat/xdr"
+)
+
+// The types used here are generated from idl_test.x.
+
+func TestIDLRoundTrip(t *testing.T) {
+	info := FileInfo{
+		Name:    "README",
+		Kind:    KindSymlink,
+		Size:    1 << 40,
+		Mode:    0644,
+		Deleted: true,
+		Mtime:   1.5e9,
+		Hash:    FileHash{1, 2, 3},
+		Blocks:  []byte{4, 5, 6},
+		Tags:    "doc",
+		Aliases: []FileName{"readme", "README.md"},
+		Next:    &FileInfoLink{Target: "README.md"},
+	}
+	for _, r0 := range []LookupResult{
+		{Kind: KindFile, Value: &FileInfo{Name: "a"}},
+		{Kind: KindSymlink, Value: &info},
+		{Kind: KindDirectory, Value: &DirListing{Path: "/", Entries: []FileInfo{info, {}}}},
+	} {
+		if err := xdr.RoundTrip(r0); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+
+		bs, err := r0.MarshalXDR()
+		if err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		var r1 LookupResult
+		if err := r1.UnmarshalXDR(bs); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		if !reflect.DeepEqual(r0, r1) {
+			t.Errorf("Expected %+v, got %+v", r0, r1)
+		}
+	}
+
+	for _, m0 := range []MaybeInfo{{Present: true, Value: &info}, {Present: false}} {
+		if err := xdr.RoundTrip(m0); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+	}
+
+	// Optional strings of an unbounded typedef.
+	note := strings.Repeat("x", 1000)
+	for _, c0 := range []FileComment{{Note: &note}, {}} {
+		if err := xdr.RoundTrip(c0); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+	}
+}
+
+func TestIDLEncoding(t *testing.T) {
+	// The wire format follows the declarations in the .x file.
+	bs, err := FileInfoLink{Target: "abcde"}.MarshalXDR()
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	expected := []byte{0, 0, 0, 5, 'a', 'b', 'c', 'd', 'e', 0, 0, 0}
+	if !bytes.Equal(bs, expected) {
+		t.Errorf("Expected %x, got %x", expected, bs)
+	}
+
+	// Constants given as sizes are enforced.
+	_, err = FileInfoLink{Target: strings.Repeat("x", MaxName+1)}.MarshalXDR()
+	if !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", err)
+	}
+
+	if KindSymlink != 2 {
+		t.Error("Expected KindSymlink to follow KindDirectory, got", int32(KindSymlink))
+	}
+}
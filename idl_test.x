@@ -0,0 +1,67 @@
+/*
+ * A sample schema in the XDR language, exercising the genxdr .x input.
+ */
+
+%// Lines starting with % are passed through by rpcgen, and skipped here.
+
+const MAX_NAME = 64;
+const MAX_ENTRIES = 16;
+
+typedef opaque file_hash[32];
+typedef string file_name<MAX_NAME>;
+typedef unsigned hyper file_size;
+typedef string file_note<>;
+
+enum file_kind {
+	KIND_FILE = 0,
+	KIND_DIRECTORY = 1,
+	KIND_SYMLINK
+};
+
+struct file_info {
+	file_name name;
+	file_kind kind;
+	file_size size;
+	int mode;
+	bool deleted;
+	double mtime;
+	file_hash hash;
+	opaque blocks<>;
+	string tags<8>;
+	file_name aliases<MAX_ENTRIES>;
+	file_info_link *next;
+};
+
+struct file_info_link {
+	file_name target;
+};
+
+struct file_comment {
+	file_note *note;
+};
+
+struct dir_listing {
+	file_name path;
+	file_info entries<MAX_ENTRIES>;
+};
+
+union lookup_result switch (file_kind kind) {
+case KIND_FILE:
+case KIND_SYMLINK:
+	file_info info;
+case KIND_DIRECTORY:
+	dir_listing listing;
+};
+
+union maybe_info switch (bool present) {
+case TRUE:
+	file_info info;
+case FALSE:
+	void;
+};
+
+program FILE_PROG {
+	version FILE_VERS {
+		lookup_result LOOKUP(file_name) = 1;
+	} = 1;
+} = 0x20000001;
@@ -0,0 +1,1297 @@
+// ************************************************************
+// This file is automatically generated by genxdr. Do not edit.
+// ************************************************************
+
+package xdr_test
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"unsafe"
+
+	"dario.cat/xdr"
+)
+
+const MaxName = 64
+
+const MaxEntries = 16
+
+// FileHash is the XDR typedef file_hash.
+type FileHash = [32]byte
+
+// FileName is the XDR typedef file_name.
+type FileName = string
+
+// FileSize is the XDR typedef file_size.
+type FileSize = uint64
+
+// FileNote is the XDR typedef file_note.
+type FileNote = string
+
+// FileKind is the XDR enum file_kind.
+//
+//xdr:enum
+type FileKind int32
+
+const (
+	KindFile      FileKind = 0
+	KindDirectory FileKind = 1
+	KindSymlink   FileKind = KindDirectory + 1
+)
+
+// FileInfo is the XDR struct file_info.
+type FileInfo struct {
+	Name    string // max:64
+	Kind    FileKind
+	Size    uint64
+	Mode    int32
+	Deleted bool
+	Mtime   float64
+	Hash    [32]byte
+	Blocks  []byte
+	Tags    string   // max:8
+	Aliases []string // max:16, 64
+	Next    *FileInfoLink
+}
+
+// FileInfoLink is the XDR struct file_info_link.
+type FileInfoLink struct {
+	Target string // max:64
+}
+
+// FileComment is the XDR struct file_comment.
+type FileComment struct {
+	Note *string
+}
+
+// DirListing is the XDR struct dir_listing.
+type DirListing struct {
+	Path    string     // max:64
+	Entries []FileInfo // max:16
+}
+
+// LookupResult is the XDR union lookup_result.
+//
+//xdr:union
+type LookupResult struct {
+	Kind  FileKind
+	Value interface{} // arms: KindFile=FileInfo, KindSymlink=FileInfo, KindDirectory=DirListing
+}
+
+// MaybeInfo is the XDR union maybe_info.
+//
+//xdr:union
+type MaybeInfo struct {
+	Present bool
+	Value   interface{} // arms: true=FileInfo, false=void
+}
+
+// XDRSize returns the XDR encoded form's size.
+func (o FileKind) XDRSize() int {
+	return 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o FileKind) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o FileKind) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the enum using the provided Marshaller.
+func (o FileKind) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalInt32(int32(o))
+	return m.Error
+}
+
+// EncodeXDR writes the enum to the provided Encoder.
+func (o FileKind) EncodeXDR(e *xdr.Encoder) error {
+	return e.EncodeInt32(int32(o))
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// enum.
+func (o *FileKind) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the enum using the provided Unmarshaller.
+// Values other than the declared FileKind constants are rejected.
+func (o *FileKind) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	v := FileKind(u.UnmarshalInt32())
+	if u.Error != nil {
+		return u.Error
+	}
+	switch v {
+	case KindFile, KindDirectory, KindSymlink:
+	default:
+		return u.Fail(xdr.InvalidEnumValue("FileKind", int32(v)))
+	}
+	*o = v
+	return nil
+}
+
+// String returns the name of the FileKind constant equal to o, or the
+// numeric value for unknown values.
+func (o FileKind) String() string {
+	switch o {
+	case KindFile:
+		return "KindFile"
+	case KindDirectory:
+		return "KindDirectory"
+	case KindSymlink:
+		return "KindSymlink"
+	}
+	return "FileKind(" + strconv.FormatInt(int64(o), 10) + ")"
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o FileKind) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o FileKind) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *FileKind) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+/*
+
+FileInfo Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Name (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                             Kind                              |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                                                               |
++                        Size (64 bits)                         +
+|                                                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                             Mode                              |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                     Deleted (V=0 or 1)                      |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                                                               |
++                        Mtime (64 bits)                        +
+|                                                               |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                   Hash (32 bytes + padding)                   \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                 Blocks (length + padded data)                 \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Tags (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                       Number of Aliases                       |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+/                                                               /
+\                Aliases (length + padded data)                 \
+/                                                               /
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                     Has Next (V=0 or 1)                     |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                    FileInfoLink Structure                     \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct FileInfo {
+	string Name<64>;
+	FileKind Kind;
+	unsigned hyper Size;
+	int Mode;
+	bool Deleted;
+	double Mtime;
+	opaque Hash[32];
+	opaque Blocks<>;
+	string Tags<8>;
+	string Aliases<16>;
+	FileInfoLink *Next;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o FileInfo) XDRSize() int {
+	s := xdr.StringSize(o.Name) +
+		o.Kind.XDRSize() + 8 + 4 + 4 + 8 + 32 +
+		xdr.BytesSize(len(o.Blocks)) +
+		xdr.StringSize(o.Tags) +
+		4 + xdr.SizeOfSlice(o.Aliases) + 4
+	if o.Next != nil {
+		s += o.Next.XDRSize()
+	}
+	return s
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o FileInfo) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o FileInfo) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o FileInfo) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Name); l > 64 {
+		return xdr.ElementSizeExceeded("Name", l, 64)
+	}
+	m.MarshalString(o.Name)
+	if err := o.Kind.MarshalXDRInto(m); err != nil {
+		return err
+	}
+	m.MarshalUint64(o.Size)
+	m.MarshalUint32(uint32(o.Mode))
+	m.MarshalBool(o.Deleted)
+	m.MarshalFloat64(o.Mtime)
+	m.MarshalFixedOpaque(o.Hash[:])
+	m.MarshalBytes(o.Blocks)
+	if l := len(o.Tags); l > 8 {
+		return xdr.ElementSizeExceeded("Tags", l, 8)
+	}
+	m.MarshalString(o.Tags)
+	if l := len(o.Aliases); l > 16 {
+		return xdr.ElementSizeExceeded("Aliases", l, 16)
+	}
+	m.MarshalUint32(uint32(len(o.Aliases)))
+	for i := range o.Aliases {
+		m.MarshalString(o.Aliases[i])
+	}
+	m.MarshalBool(o.Next != nil)
+	if o.Next != nil {
+		if err := o.Next.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o FileInfo) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Name); l > 64 {
+		return xdr.ElementSizeExceeded("Name", l, 64)
+	}
+	e.EncodeString(o.Name)
+	if err := o.Kind.EncodeXDR(e); err != nil {
+		return err
+	}
+	e.EncodeUint64(o.Size)
+	e.EncodeUint32(uint32(o.Mode))
+	e.EncodeBool(o.Deleted)
+	e.EncodeFloat64(o.Mtime)
+	e.EncodeFixedOpaque(o.Hash[:])
+	e.EncodeBytes(o.Blocks)
+	if l := len(o.Tags); l > 8 {
+		return xdr.ElementSizeExceeded("Tags", l, 8)
+	}
+	e.EncodeString(o.Tags)
+	if l := len(o.Aliases); l > 16 {
+		return xdr.ElementSizeExceeded("Aliases", l, 16)
+	}
+	e.EncodeUint32(uint32(len(o.Aliases)))
+	for i := range o.Aliases {
+		e.EncodeString(o.Aliases[i])
+	}
+	e.EncodeBool(o.Next != nil)
+	if o.Next != nil {
+		if err := o.Next.EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *FileInfo) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *FileInfo) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *FileInfo) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if _, err := xdr.CheckLength("Name", u.PeekUint32(), 64); err != nil {
+		return u.Fail(err)
+	}
+	o.Name = u.UnmarshalStringMax(64)
+	if err := (&o.Kind).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	o.Size = u.UnmarshalUint64()
+	o.Mode = int32(u.UnmarshalUint32())
+	o.Deleted = u.UnmarshalBool()
+	o.Mtime = u.UnmarshalFloat64()
+	copy(o.Hash[:], u.UnmarshalFixedOpaque(32))
+	o.Blocks = u.UnmarshalBytes()
+	if _, err := xdr.CheckLength("Tags", u.PeekUint32(), 8); err != nil {
+		return u.Fail(err)
+	}
+	o.Tags = u.UnmarshalStringMax(8)
+	_AliasesSize := u.UnmarshalLength("Aliases", 16)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _AliasesSize == 0 {
+		o.Aliases = nil
+	} else {
+		if !u.Require(_AliasesSize, 4) {
+			return u.Error
+		}
+		if _AliasesSize <= cap(o.Aliases) {
+			for i := _AliasesSize; i < len(o.Aliases); i++ {
+				o.Aliases[i] = ""
+			}
+			o.Aliases = o.Aliases[:_AliasesSize]
+		} else {
+			_AliasesCap := u.Capacity("FileInfo.Aliases", _AliasesSize)
+			if !u.Alloc(_AliasesCap * int(unsafe.Sizeof(o.Aliases[0]))) {
+				return u.Error
+			}
+			o.Aliases = make([]string, _AliasesSize, _AliasesCap)
+		}
+		for i := range o.Aliases {
+			if _, err := xdr.CheckLength("Aliases", u.PeekUint32(), 64); err != nil {
+				return u.Fail(err)
+			}
+			o.Aliases[i] = u.UnmarshalStringMax(64)
+		}
+	}
+	if u.UnmarshalBool() {
+		if o.Next == nil {
+			o.Next = new(FileInfoLink)
+		}
+		if err := o.Next.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+	} else {
+		o.Next = nil
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o FileInfo) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o FileInfo) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *FileInfo) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+// Floats are compared by their bits, so NaN equals itself
+// while 0 and -0 differ.
+func (o FileInfo) Equal(p FileInfo) bool {
+	if o.Name != p.Name {
+		return false
+	}
+	if o.Kind != p.Kind {
+		return false
+	}
+	if o.Size != p.Size {
+		return false
+	}
+	if o.Mode != p.Mode {
+		return false
+	}
+	if o.Deleted != p.Deleted {
+		return false
+	}
+	if math.Float64bits(o.Mtime) != math.Float64bits(p.Mtime) {
+		return false
+	}
+	if o.Hash != p.Hash {
+		return false
+	}
+	if string(o.Blocks) != string(p.Blocks) {
+		return false
+	}
+	if o.Tags != p.Tags {
+		return false
+	}
+	if len(o.Aliases) != len(p.Aliases) {
+		return false
+	}
+	for i := range o.Aliases {
+		if o.Aliases[i] != p.Aliases[i] {
+			return false
+		}
+	}
+	if (o.Next == nil) != (p.Next == nil) || o.Next != nil && !o.Next.Equal(*p.Next) {
+		return false
+	}
+	return true
+}
+
+/*
+
+FileInfoLink Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                 Target (length + padded data)                 \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct FileInfoLink {
+	string Target<64>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o FileInfoLink) XDRSize() int {
+	return xdr.StringSize(o.Target)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o FileInfoLink) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o FileInfoLink) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o FileInfoLink) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Target); l > 64 {
+		return xdr.ElementSizeExceeded("Target", l, 64)
+	}
+	m.MarshalString(o.Target)
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o FileInfoLink) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Target); l > 64 {
+		return xdr.ElementSizeExceeded("Target", l, 64)
+	}
+	e.EncodeString(o.Target)
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *FileInfoLink) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *FileInfoLink) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *FileInfoLink) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if _, err := xdr.CheckLength("Target", u.PeekUint32(), 64); err != nil {
+		return u.Fail(err)
+	}
+	o.Target = u.UnmarshalStringMax(64)
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o FileInfoLink) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o FileInfoLink) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *FileInfoLink) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o FileInfoLink) Equal(p FileInfoLink) bool {
+	if o.Target != p.Target {
+		return false
+	}
+	return true
+}
+
+/*
+
+FileComment Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                     Has Note (V=0 or 1)                     |V|
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Note (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct FileComment {
+	string *Note<>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o FileComment) XDRSize() int {
+	s := 4
+	if o.Note != nil {
+		s += xdr.StringSize(*o.Note)
+	}
+	return s
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o FileComment) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o FileComment) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o FileComment) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalBool(o.Note != nil)
+	if o.Note != nil {
+		m.MarshalString(*o.Note)
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o FileComment) EncodeXDR(e *xdr.Encoder) error {
+	e.EncodeBool(o.Note != nil)
+	if o.Note != nil {
+		e.EncodeString(*o.Note)
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *FileComment) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *FileComment) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *FileComment) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if u.UnmarshalBool() {
+		if o.Note == nil {
+			o.Note = new(string)
+		}
+		*o.Note = u.UnmarshalString()
+	} else {
+		o.Note = nil
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o FileComment) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o FileComment) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *FileComment) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one.
+func (o FileComment) Equal(p FileComment) bool {
+	if (o.Note == nil) != (p.Note == nil) || o.Note != nil && *o.Note != *p.Note {
+		return false
+	}
+	return true
+}
+
+/*
+
+DirListing Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Path (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                       Number of Entries                       |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\               Zero or more FileInfo Structures                \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct DirListing {
+	string Path<64>;
+	FileInfo Entries<16>;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o DirListing) XDRSize() int {
+	return xdr.StringSize(o.Path) +
+		4 + xdr.SizeOfSlice(o.Entries)
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o DirListing) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o DirListing) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the struct using the provided Marshaller.
+func (o DirListing) MarshalXDRInto(m *xdr.Marshaller) error {
+	if l := len(o.Path); l > 64 {
+		return xdr.ElementSizeExceeded("Path", l, 64)
+	}
+	m.MarshalString(o.Path)
+	if l := len(o.Entries); l > 16 {
+		return xdr.ElementSizeExceeded("Entries", l, 16)
+	}
+	m.MarshalUint32(uint32(len(o.Entries)))
+	for i := range o.Entries {
+		if err := o.Entries[i].MarshalXDRInto(m); err != nil {
+			return err
+		}
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the struct to the provided Encoder.
+func (o DirListing) EncodeXDR(e *xdr.Encoder) error {
+	if l := len(o.Path); l > 64 {
+		return xdr.ElementSizeExceeded("Path", l, 64)
+	}
+	e.EncodeString(o.Path)
+	if l := len(o.Entries); l > 16 {
+		return xdr.ElementSizeExceeded("Entries", l, 16)
+	}
+	e.EncodeUint32(uint32(len(o.Entries)))
+	for i := range o.Entries {
+		if err := o.Entries[i].EncodeXDR(e); err != nil {
+			return err
+		}
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// struct.
+func (o *DirListing) UnmarshalXDR(bs []byte) error {
+	return o.UnmarshalXDRInto(bs, nil)
+}
+
+// UnmarshalXDRInto is like UnmarshalXDR, but allocates slices and maps,
+// here and in nested structs, at the capacities given by hints.
+func (o *DirListing) UnmarshalXDRInto(bs []byte, hints xdr.Hints) error {
+	u := &xdr.Unmarshaller{Data: bs, Hints: hints}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the struct using the provided Unmarshaller.
+// Slice fields reuse their backing arrays when they have enough capacity, so
+// decoding many messages into the same struct avoids reallocating them.
+func (o *DirListing) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if _, err := xdr.CheckLength("Path", u.PeekUint32(), 64); err != nil {
+		return u.Fail(err)
+	}
+	o.Path = u.UnmarshalStringMax(64)
+	_EntriesSize := u.UnmarshalLength("Entries", 16)
+	if u.Error != nil {
+		return u.Error
+	}
+	if _EntriesSize == 0 {
+		o.Entries = nil
+	} else {
+		if !u.Require(_EntriesSize, 80) {
+			return u.Error
+		}
+		if _EntriesSize <= cap(o.Entries) {
+			o.Entries = o.Entries[:_EntriesSize]
+		} else {
+			_EntriesCap := u.Capacity("DirListing.Entries", _EntriesSize)
+			if !u.Alloc(_EntriesCap * int(unsafe.Sizeof(o.Entries[0]))) {
+				return u.Error
+			}
+			o.Entries = make([]FileInfo, _EntriesSize, _EntriesCap)
+		}
+		for i := range o.Entries {
+			if err := (&o.Entries[i]).UnmarshalXDRFrom(u); err != nil {
+				return err
+			}
+		}
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o DirListing) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o DirListing) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *DirListing) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p are equal, comparing the encoded fields one
+// by one. Slices are equal when their elements are, so nil and empty
+// slices, which encode alike, are equal.
+func (o DirListing) Equal(p DirListing) bool {
+	if o.Path != p.Path {
+		return false
+	}
+	if len(o.Entries) != len(p.Entries) {
+		return false
+	}
+	for i := range o.Entries {
+		if !o.Entries[i].Equal(p.Entries[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+
+union LookupResult switch (FileKind Kind) {
+case KindFile:
+	FileInfo Value;
+case KindSymlink:
+	FileInfo Value;
+case KindDirectory:
+	DirListing Value;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o LookupResult) XDRSize() int {
+	switch o.Kind {
+	case KindFile:
+		if v, ok := o.Value.(*FileInfo); ok {
+			return 4 + v.XDRSize()
+		}
+	case KindSymlink:
+		if v, ok := o.Value.(*FileInfo); ok {
+			return 4 + v.XDRSize()
+		}
+	case KindDirectory:
+		if v, ok := o.Value.(*DirListing); ok {
+			return 4 + v.XDRSize()
+		}
+	}
+	return 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o LookupResult) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o LookupResult) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the union using the provided Marshaller.
+func (o LookupResult) MarshalXDRInto(m *xdr.Marshaller) error {
+	switch o.Kind {
+	case KindFile:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			return xdr.InvalidUnionArm("LookupResult", o.Kind)
+		}
+		if err := o.Kind.MarshalXDRInto(m); err != nil {
+			return err
+		}
+		if err := v.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	case KindSymlink:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			return xdr.InvalidUnionArm("LookupResult", o.Kind)
+		}
+		if err := o.Kind.MarshalXDRInto(m); err != nil {
+			return err
+		}
+		if err := v.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	case KindDirectory:
+		v, ok := o.Value.(*DirListing)
+		if !ok {
+			return xdr.InvalidUnionArm("LookupResult", o.Kind)
+		}
+		if err := o.Kind.MarshalXDRInto(m); err != nil {
+			return err
+		}
+		if err := v.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	default:
+		return xdr.InvalidUnionArm("LookupResult", o.Kind)
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the union to the provided Encoder.
+func (o LookupResult) EncodeXDR(e *xdr.Encoder) error {
+	switch o.Kind {
+	case KindFile:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			return xdr.InvalidUnionArm("LookupResult", o.Kind)
+		}
+		if err := o.Kind.EncodeXDR(e); err != nil {
+			return err
+		}
+		if err := v.EncodeXDR(e); err != nil {
+			return err
+		}
+	case KindSymlink:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			return xdr.InvalidUnionArm("LookupResult", o.Kind)
+		}
+		if err := o.Kind.EncodeXDR(e); err != nil {
+			return err
+		}
+		if err := v.EncodeXDR(e); err != nil {
+			return err
+		}
+	case KindDirectory:
+		v, ok := o.Value.(*DirListing)
+		if !ok {
+			return xdr.InvalidUnionArm("LookupResult", o.Kind)
+		}
+		if err := o.Kind.EncodeXDR(e); err != nil {
+			return err
+		}
+		if err := v.EncodeXDR(e); err != nil {
+			return err
+		}
+	default:
+		return xdr.InvalidUnionArm("LookupResult", o.Kind)
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// union.
+func (o *LookupResult) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the union using the provided Unmarshaller.
+func (o *LookupResult) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	if err := (&o.Kind).UnmarshalXDRFrom(u); err != nil {
+		return err
+	}
+	if u.Error != nil {
+		return u.Error
+	}
+	switch o.Kind {
+	case KindFile:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			v = new(FileInfo)
+		}
+		if err := v.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+		o.Value = v
+	case KindSymlink:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			v = new(FileInfo)
+		}
+		if err := v.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+		o.Value = v
+	case KindDirectory:
+		v, ok := o.Value.(*DirListing)
+		if !ok {
+			v = new(DirListing)
+		}
+		if err := v.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+		o.Value = v
+	default:
+		u.Fail(xdr.InvalidUnionArm("LookupResult", o.Kind))
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o LookupResult) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o LookupResult) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *LookupResult) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p have the same discriminant and equal arm
+// values.
+func (o LookupResult) Equal(p LookupResult) bool {
+	if o.Kind != p.Kind {
+		return false
+	}
+	switch o.Kind {
+	case KindFile:
+		a, _ := o.Value.(*FileInfo)
+		b, _ := p.Value.(*FileInfo)
+		return a == b || a != nil && b != nil && a.Equal(*b)
+	case KindSymlink:
+		a, _ := o.Value.(*FileInfo)
+		b, _ := p.Value.(*FileInfo)
+		return a == b || a != nil && b != nil && a.Equal(*b)
+	case KindDirectory:
+		a, _ := o.Value.(*DirListing)
+		b, _ := p.Value.(*DirListing)
+		return a == b || a != nil && b != nil && a.Equal(*b)
+	}
+	return true
+}
+
+/*
+
+union MaybeInfo switch (bool Present) {
+case true:
+	FileInfo Value;
+case false:
+	void;
+}
+
+*/
+
+// XDRSize returns the XDR encoded form's size.
+func (o MaybeInfo) XDRSize() int {
+	switch o.Present {
+	case true:
+		if v, ok := o.Value.(*FileInfo); ok {
+			return 4 + v.XDRSize()
+		}
+	}
+	return 4
+}
+
+// MarshalXDR returns the XDR encoding.
+func (o MaybeInfo) MarshalXDR() ([]byte, error) {
+	m := xdr.NewMarshallerSize(o.XDRSize())
+	return m.Data, o.MarshalXDRInto(m)
+}
+
+// MustMarshalXDR returns the XDR encoding. MustMarshalXDR
+// panics in case of error.
+func (o MaybeInfo) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+// MarshalXDRInto marshals the union using the provided Marshaller.
+func (o MaybeInfo) MarshalXDRInto(m *xdr.Marshaller) error {
+	switch o.Present {
+	case true:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			return xdr.InvalidUnionArm("MaybeInfo", o.Present)
+		}
+		m.MarshalBool(o.Present)
+		if err := v.MarshalXDRInto(m); err != nil {
+			return err
+		}
+	case false:
+		m.MarshalBool(o.Present)
+	default:
+		return xdr.InvalidUnionArm("MaybeInfo", o.Present)
+	}
+	return m.Error
+}
+
+// EncodeXDR writes the union to the provided Encoder.
+func (o MaybeInfo) EncodeXDR(e *xdr.Encoder) error {
+	switch o.Present {
+	case true:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			return xdr.InvalidUnionArm("MaybeInfo", o.Present)
+		}
+		e.EncodeBool(o.Present)
+		if err := v.EncodeXDR(e); err != nil {
+			return err
+		}
+	case false:
+		e.EncodeBool(o.Present)
+	default:
+		return xdr.InvalidUnionArm("MaybeInfo", o.Present)
+	}
+	return e.Err()
+}
+
+// UnmarshalXDR parses the XDR-encoded data and stores the result in the
+// union.
+func (o *MaybeInfo) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+// UnmarshalXDRFrom unmarshals the union using the provided Unmarshaller.
+func (o *MaybeInfo) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	if !u.Enter() {
+		return u.Error
+	}
+	defer u.Leave()
+	o.Present = u.UnmarshalBool()
+	if u.Error != nil {
+		return u.Error
+	}
+	switch o.Present {
+	case true:
+		v, ok := o.Value.(*FileInfo)
+		if !ok {
+			v = new(FileInfo)
+		}
+		if err := v.UnmarshalXDRFrom(u); err != nil {
+			return err
+		}
+		o.Value = v
+	case false:
+		o.Value = nil
+	default:
+		u.Fail(xdr.InvalidUnionArm("MaybeInfo", o.Present))
+	}
+	return u.Error
+}
+
+// MarshalXDRTo writes the XDR encoding to w, streaming it through an
+// xdr.Encoder instead of marshalling it into memory first.
+func (o MaybeInfo) MarshalXDRTo(w io.Writer) error {
+	e := xdr.NewEncoder(w)
+	if err := o.EncodeXDR(e); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// MarshalBinary returns the XDR encoding, implementing
+// encoding.BinaryMarshaler.
+func (o MaybeInfo) MarshalBinary() ([]byte, error) {
+	return o.MarshalXDR()
+}
+
+// UnmarshalBinary parses the XDR-encoded data, implementing
+// encoding.BinaryUnmarshaler.
+func (o *MaybeInfo) UnmarshalBinary(bs []byte) error {
+	return o.UnmarshalXDR(bs)
+}
+
+// Equal reports whether o and p have the same discriminant and equal arm
+// values.
+func (o MaybeInfo) Equal(p MaybeInfo) bool {
+	if o.Present != p.Present {
+		return false
+	}
+	switch o.Present {
+	case true:
+		a, _ := o.Value.(*FileInfo)
+		b, _ := p.Value.(*FileInfo)
+		return a == b || a != nil && b != nil && a.Equal(*b)
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+// StringInterner hands out one shared string per distinct byte sequence, so
+// that decoding values which recur from message to message, such as tags or
+// names, allocates each of them once only. Set it as the Interner of an
+// Unmarshaller, and keep it across messages, for UnmarshalString and
+// UnmarshalStringMax to use it.
+//
+// The zero value interns every string it sees. As the strings are kept for
+// the lifetime of the StringInterner, input from untrusted sources should
+// use NewStringInterner to bound their number. A StringInterner is not safe
+// for concurrent use.
+type StringInterner struct {
+	strings map[string]string
+	max     int
+}
+
+// NewStringInterner returns a StringInterner keeping at most max strings.
+// Once it is full, strings not seen before are returned without being
+// interned. A max of zero removes the limit.
+func NewStringInterner(max int) *StringInterner {
+	return &StringInterner{max: max}
+}
+
+// Intern returns the shared string with the contents of b, which is only
+// allocated the first time it is seen.
+func (si *StringInterner) Intern(b []byte) string {
+	if s, ok := si.lookup(b); ok {
+		return s
+	}
+	return si.add(string(b))
+}
+
+// Len returns the number of strings interned.
+func (si *StringInterner) Len() int {
+	return len(si.strings)
+}
+
+// lookup returns the shared string with the contents of b, if there is one,
+// without allocating.
+func (si *StringInterner) lookup(b []byte) (string, bool) {
+	s, ok := si.strings[string(b)]
+	return s, ok
+}
+
+// add interns s, if there is room for it, and returns it.
+func (si *StringInterner) add(s string) string {
+	if si.max > 0 && len(si.strings) >= si.max {
+		return s
+	}
+	if si.strings == nil {
+		si.strings = make(map[string]string)
+	}
+	si.strings[s] = s
+	return s
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"reflect"
+	"sort"
+)
+
+// SortedKeys returns the keys of m in increasing order. Maps are encoded as
+// their key/value pairs in this order, so that the encoding of a map does not
+// depend on Go's iteration order. This function is used by the generated
+// marshalling code.
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SizeOfMap returns the XDR encoded size of the key/value pairs of the given
+// map[string]T, not counting the number of pairs before them. Supported
+// types for T are those of SizeOfSlice, bool and the integer and float
+// types. SizeOfMap panics if the parameter is not such a map. This function
+// is used by the generated marshalling code.
+func SizeOfMap(mm interface{}) int {
+	l := 0
+	switch mm := mm.(type) {
+	case map[string]string:
+		for k, v := range mm {
+			l += StringSize(k) + StringSize(v)
+		}
+
+	case map[string][]byte:
+		for k, v := range mm {
+			l += StringSize(k) + BytesSize(len(v))
+		}
+
+	default:
+		it := reflect.ValueOf(mm).MapRange()
+		for it.Next() {
+			l += BytesSize(it.Key().Len())
+			v := it.Value()
+			if s, ok := v.Interface().(Sizer); ok {
+				l += s.XDRSize()
+				continue
+			}
+			switch v.Kind() {
+			case reflect.String:
+				l += BytesSize(v.Len())
+			case reflect.Slice:
+				if v.Type().Elem().Kind() != reflect.Uint8 {
+					panic("xdr: unsupported map value type " + v.Type().String())
+				}
+				l += BytesSize(v.Len())
+			case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+				l += 8
+			case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32,
+				reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Float32:
+				l += 4
+			default:
+				panic("xdr: unsupported map value type " + v.Type().String())
+			}
+		}
+	}
+
+	return l
+}
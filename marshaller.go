@@ -4,7 +4,13 @@
 
 package xdr
 
-import "io"
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
 
 // Marshaller is a thin wrapper around a byte buffer. The buffer must be
 // of sufficient size to hold the complete marshalled object, or an
@@ -12,13 +18,86 @@ import "io"
 // individually return an error - the intention is that multiple fields are
 // marshalled in rapid succession, followed by a check of the Error field on
 // the Marshaller.
+//
+// When ValidateUTF8 is set, MarshalString rejects strings that are not valid
+// UTF-8 instead of copying them as is.
+//
+// ByteOrder, if set, replaces the big-endian byte order that XDR mandates
+// for integers, size prefixes included; see the Unmarshaller field of the
+// same name.
+//
+// Marshalling is deterministic, so that encodings can be hashed or compared:
+// the same value always results in the same bytes, whatever a reused buffer
+// held before. Booleans are written as 0 or 1 and padding as zero bytes.
+// Only a ByteOrder, or data copied as is by MarshalRaw, departs from the
+// canonical encoding. An Unmarshaller with Strict set rejects input that is
+// not canonical.
 type Marshaller struct {
-	Data  []byte
-	Error error
+	Data         []byte
+	Error        error
+	ValidateUTF8 bool
+	ByteOrder    binary.ByteOrder
 
 	offset int
 }
 
+// NewMarshallerSize returns a Marshaller with a buffer of n bytes.
+func NewMarshallerSize(n int) *Marshaller {
+	return &Marshaller{Data: make([]byte, n)}
+}
+
+// NewMarshaller returns a Marshaller that appends to buf, keeping its
+// contents. Call Grow to make room before marshalling; as long as buf has
+// enough spare capacity this doesn't reallocate, so several records can be
+// marshalled into one packet without copying:
+//
+//	m := xdr.NewMarshaller(packet[:0])
+//	for _, r := range records {
+//		m.Grow(r.XDRSize())
+//		r.MarshalXDRInto(m)
+//	}
+//	packet = m.Bytes()
+func NewMarshaller(buf []byte) *Marshaller {
+	return &Marshaller{Data: buf, offset: len(buf)}
+}
+
+// Bytes returns the data marshalled so far, including any contents of the
+// buffer passed to NewMarshaller.
+func (m *Marshaller) Bytes() []byte {
+	return m.Data[:m.offset]
+}
+
+// Grow makes room in the buffer for at least n more bytes after the data
+// marshalled so far, reallocating it if necessary. Marshalled data is kept.
+func (m *Marshaller) Grow(n int) {
+	if n < 0 {
+		panic("xdr: negative Marshaller.Grow count")
+	}
+	if len(m.Data)-m.offset >= n {
+		return
+	}
+	if cap(m.Data)-m.offset >= n {
+		m.Data = m.Data[:m.offset+n]
+		return
+	}
+
+	buf := make([]byte, m.offset+n, 2*cap(m.Data)+n)
+	copy(buf, m.Data[:m.offset])
+	m.Data = buf
+}
+
+// WriteTo writes the marshalled data to w, implementing io.WriterTo. If a
+// previous Marshal... call failed, that error is returned and nothing is
+// written.
+func (m *Marshaller) WriteTo(w io.Writer) (int64, error) {
+	if m.Error != nil {
+		return 0, m.Error
+	}
+
+	n, err := w.Write(m.Data[:m.offset])
+	return int64(n), err
+}
+
 // MarshalRaw copies the raw bytes to the buffer, without a size prefix or
 // padding. This is suitable for appending data already in XDR format from
 // another source.
@@ -34,12 +113,42 @@ func (m *Marshaller) MarshalRaw(bs []byte) {
 	m.offset += copy(m.Data[m.offset:], bs)
 }
 
+// MarshalFixedOpaque copies the bytes to the buffer, without a size prefix
+// but with correct padding. This is the encoding of XDR fixed-length opaque
+// data.
+func (m *Marshaller) MarshalFixedOpaque(bs []byte) {
+	if m.Error != nil {
+		return
+	}
+	if len(m.Data) < m.offset+len(bs)+Padding(len(bs)) {
+		m.Error = io.ErrShortBuffer
+		return
+	}
+
+	m.offset += copy(m.Data[m.offset:], bs)
+	m.offset += copy(m.Data[m.offset:], padBytes[:Padding(len(bs))])
+}
+
 // MarshalString appends the string to the buffer, with a size prefix and
-// correct padding.
+// correct padding. If ValidateUTF8 is set, s must be valid UTF-8.
 func (m *Marshaller) MarshalString(s string) {
+	m.MarshalStringMax(s, 0)
+}
+
+// MarshalStringMax appends the string to the buffer like MarshalString,
+// provided it is at most max bytes long if max is positive.
+func (m *Marshaller) MarshalStringMax(s string, max int) {
 	if m.Error != nil {
 		return
 	}
+	if max > 0 && len(s) > max {
+		m.Error = ElementSizeExceeded("bytes field", len(s), max)
+		return
+	}
+	if m.ValidateUTF8 && !utf8.ValidString(s) {
+		m.Error = ErrInvalidUTF8
+		return
+	}
 	if len(m.Data) < m.offset+4+len(s)+Padding(len(s)) {
 		m.Error = io.ErrShortBuffer
 		return
@@ -53,9 +162,19 @@ func (m *Marshaller) MarshalString(s string) {
 // MarshalBytes appends the bytes to the buffer, with a size prefix and
 // correct padding.
 func (m *Marshaller) MarshalBytes(bs []byte) {
+	m.MarshalBytesMax(bs, 0)
+}
+
+// MarshalBytesMax appends the bytes to the buffer like MarshalBytes,
+// provided there are at most max of them if max is positive.
+func (m *Marshaller) MarshalBytesMax(bs []byte, max int) {
 	if m.Error != nil {
 		return
 	}
+	if max > 0 && len(bs) > max {
+		m.Error = ElementSizeExceeded("bytes field", len(bs), max)
+		return
+	}
 	if len(m.Data) < m.offset+4+len(bs)+Padding(len(bs)) {
 		m.Error = io.ErrShortBuffer
 		return
@@ -66,6 +185,77 @@ func (m *Marshaller) MarshalBytes(bs []byte) {
 	m.offset += copy(m.Data[m.offset:], padBytes[:Padding(len(bs))])
 }
 
+// MarshalBytesN appends the first n bytes of bs to the buffer like
+// MarshalBytes, for marshalling a prefix of a scratch buffer. An n outside
+// the length of bs sets an ElementSizeExceeded error.
+func (m *Marshaller) MarshalBytesN(bs []byte, n int) {
+	if m.Error != nil {
+		return
+	}
+	if n < 0 || n > len(bs) {
+		m.Error = ElementSizeExceeded("bytes field", n, len(bs))
+		return
+	}
+
+	m.MarshalBytesMax(bs[:n], 0)
+}
+
+// MarshalXDRBlob appends data that is already XDR encoded as
+// variable-length opaque data, with a size prefix. As XDR data is always a
+// multiple of four bytes long, any other length sets ErrUnalignedBlob.
+// UnmarshalNested reads it back.
+func (m *Marshaller) MarshalXDRBlob(bs []byte) {
+	if m.Error != nil {
+		return
+	}
+	if len(bs)%4 != 0 {
+		m.Error = ErrUnalignedBlob
+		return
+	}
+
+	m.MarshalBytes(bs)
+}
+
+// MarshalNested appends whatever fn marshals as variable-length opaque
+// data, that is with a size prefix and correct padding, so that a decoder can
+// skip the nested message without understanding it. fn marshals into m in
+// place; the size prefix is filled in once it returns. See
+// Unmarshaller.UnmarshalNested for the decoding side.
+func (m *Marshaller) MarshalNested(fn func(m *Marshaller)) {
+	if m.Error != nil {
+		return
+	}
+
+	start := m.offset
+	m.MarshalUint32(0)
+	if m.Error != nil {
+		return
+	}
+	fn(m)
+	if m.Error != nil {
+		return
+	}
+
+	l := m.offset - start - 4
+	if err := checkEncodedLength("nested message", l); err != nil {
+		m.Error = err
+		return
+	}
+	m.putUint32(m.Data[start:], uint32(l))
+	m.MarshalRaw(padBytes[:Padding(l)])
+}
+
+// MarshalUnion appends a discriminated union: the discriminant, as an int,
+// followed by whatever arm marshals as the arm it selects. arm may be nil for
+// a void arm. See Unmarshaller.UnmarshalUnion for the decoding side.
+func (m *Marshaller) MarshalUnion(discriminant int32, arm func(m *Marshaller)) {
+	m.MarshalInt32(discriminant)
+	if m.Error != nil || arm == nil {
+		return
+	}
+	arm(m)
+}
+
 // MarshalBool appends the bool to the buffer, as an uint32.
 func (m *Marshaller) MarshalBool(v bool) {
 	if v {
@@ -95,10 +285,7 @@ func (m *Marshaller) MarshalUint32(v uint32) {
 		return
 	}
 
-	m.Data[m.offset+0] = byte(v >> 24)
-	m.Data[m.offset+1] = byte(v >> 16)
-	m.Data[m.offset+2] = byte(v >> 8)
-	m.Data[m.offset+3] = byte(v)
+	m.putUint32(m.Data[m.offset:], v)
 	m.offset += 4
 }
 
@@ -112,13 +299,155 @@ func (m *Marshaller) MarshalUint64(v uint64) {
 		return
 	}
 
-	m.Data[m.offset+0] = byte(v >> 56)
-	m.Data[m.offset+1] = byte(v >> 48)
-	m.Data[m.offset+2] = byte(v >> 40)
-	m.Data[m.offset+3] = byte(v >> 32)
-	m.Data[m.offset+4] = byte(v >> 24)
-	m.Data[m.offset+5] = byte(v >> 16)
-	m.Data[m.offset+6] = byte(v >> 8)
-	m.Data[m.offset+7] = byte(v)
+	m.putUint64(m.Data[m.offset:], v)
 	m.offset += 8
 }
+
+// MarshalUint128 appends the 128-bit integer to the buffer, as 16 bytes in
+// big-endian order. v[0] holds the high and v[1] the low 64 bits; signed
+// values are stored in two's complement.
+func (m *Marshaller) MarshalUint128(v [2]uint64) {
+	if m.Error != nil {
+		return
+	}
+	if len(m.Data) < m.offset+16 {
+		m.Error = io.ErrShortBuffer
+		return
+	}
+
+	m.MarshalUint64(v[0])
+	m.MarshalUint64(v[1])
+}
+
+// MarshalUint32Slice appends the number of elements in vs, followed by each
+// uint32.
+func (m *Marshaller) MarshalUint32Slice(vs []uint32) {
+	if m.Error != nil {
+		return
+	}
+	if len(m.Data) < m.offset+4+4*len(vs) {
+		m.Error = io.ErrShortBuffer
+		return
+	}
+
+	m.MarshalUint32(uint32(len(vs)))
+	for _, v := range vs {
+		m.MarshalUint32(v)
+	}
+}
+
+// MarshalUint64Slice appends the number of elements in vs, followed by each
+// uint64.
+func (m *Marshaller) MarshalUint64Slice(vs []uint64) {
+	if m.Error != nil {
+		return
+	}
+	if len(m.Data) < m.offset+4+8*len(vs) {
+		m.Error = io.ErrShortBuffer
+		return
+	}
+
+	m.MarshalUint32(uint32(len(vs)))
+	for _, v := range vs {
+		m.MarshalUint64(v)
+	}
+}
+
+// MarshalBoolSlice appends the number of elements in vs, followed by each
+// bool as an uint32.
+func (m *Marshaller) MarshalBoolSlice(vs []bool) {
+	if m.Error != nil {
+		return
+	}
+	if len(m.Data) < m.offset+4+4*len(vs) {
+		m.Error = io.ErrShortBuffer
+		return
+	}
+
+	m.MarshalUint32(uint32(len(vs)))
+	for _, v := range vs {
+		m.MarshalBool(v)
+	}
+}
+
+// MarshalInt8 appends the int8 to the buffer, as an uint32.
+func (m *Marshaller) MarshalInt8(v int8) {
+	m.MarshalUint8(uint8(v))
+}
+
+// MarshalInt16 appends the int16 to the buffer, as an uint32.
+func (m *Marshaller) MarshalInt16(v int16) {
+	m.MarshalUint16(uint16(v))
+}
+
+// MarshalInt32 appends the int32 to the buffer.
+func (m *Marshaller) MarshalInt32(v int32) {
+	m.MarshalUint32(uint32(v))
+}
+
+// MarshalInt64 appends the int64 to the buffer.
+func (m *Marshaller) MarshalInt64(v int64) {
+	m.MarshalUint64(uint64(v))
+}
+
+// MarshalTime appends the instant t as a hyper holding the seconds since the
+// Unix epoch, followed by an unsigned int holding the nanoseconds within that
+// second, as the nfstime4 type of NFSv4 does. The location of t, and its
+// monotonic clock reading, are not encoded.
+func (m *Marshaller) MarshalTime(t time.Time) {
+	if m.Error != nil {
+		return
+	}
+	if len(m.Data) < m.offset+12 {
+		m.Error = io.ErrShortBuffer
+		return
+	}
+
+	m.MarshalInt64(t.Unix())
+	m.MarshalUint32(uint32(t.Nanosecond()))
+}
+
+// MarshalFloat16 appends the float32 to the buffer as an IEEE 754
+// half-precision value, in the low-order bytes of an uint32 like an uint16.
+// The value is rounded to nearest, ties to even; magnitudes beyond the
+// binary16 range become infinities or zeros.
+func (m *Marshaller) MarshalFloat16(v float32) {
+	m.MarshalUint16(float32ToFloat16(v))
+}
+
+// MarshalFloat32 appends the float32 to the buffer, as its IEEE 754 bit
+// representation.
+func (m *Marshaller) MarshalFloat32(v float32) {
+	m.MarshalUint32(math.Float32bits(v))
+}
+
+// MarshalFloat64 appends the float64 to the buffer, as its IEEE 754 bit
+// representation.
+func (m *Marshaller) MarshalFloat64(v float64) {
+	m.MarshalUint64(math.Float64bits(v))
+}
+
+// MarshalQuadRaw appends an XDR quadruple-precision float, given as its 16
+// bytes in big-endian order, to the buffer. Go has no such type, so the bytes
+// are copied as they are, uninterpreted; ByteOrder does not apply to them.
+func (m *Marshaller) MarshalQuadRaw(v [16]byte) {
+	m.MarshalRaw(v[:])
+}
+
+// putUint32 encodes v at the start of b in the Marshaller's byte order.
+func (m *Marshaller) putUint32(b []byte, v uint32) {
+	if m.ByteOrder != nil {
+		m.ByteOrder.PutUint32(b, v)
+		return
+	}
+	PutUint32BE(b, v)
+}
+
+// putUint64 encodes v at the start of b in the Marshaller's byte order.
+func (m *Marshaller) putUint64(b []byte, v uint64) {
+	if m.ByteOrder != nil {
+		m.ByteOrder.PutUint64(b, v)
+		return
+	}
+	PutUint64BE(b, v)
+}
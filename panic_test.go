@@ -0,0 +1,77 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// The types in this file are generated with -panic.
+
+// Priority is an XDR enum.
+//
+//xdr:enum
+type Priority int32
+
+const (
+	PriorityLow Priority = iota
+	PriorityHigh
+)
+
+type TrustedStruct struct {
+	Name     string // max:8
+	Priority Priority
+}
+
+func TestPanicOnError(t *testing.T) {
+	t0 := TrustedStruct{Name: "name", Priority: PriorityHigh}
+	var t1 TrustedStruct
+	if err := t1.UnmarshalXDR(t0.MustMarshalXDR()); err != nil || t1 != t0 {
+		t.Fatalf("Expected %+v, got %+v (%v)", t0, t1, err)
+	}
+
+	for _, bs := range [][]byte{
+		{0, 0, 0, 9},
+		{0, 0, 0, 0, 0, 0, 0, 2},
+		{0, 0, 0, 1},
+	} {
+		func() {
+			defer func() {
+				if _, ok := recover().(error); !ok {
+					t.Errorf("Expected a panic with an error decoding %x", bs)
+				}
+			}()
+			t1.UnmarshalXDR(bs)
+		}()
+	}
+
+	// UnmarshalXDRInto and UnmarshalXDRFrom, which compose with other
+	// decoding, still return their errors.
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				t.Error("Unexpected panic", err)
+			}
+		}()
+		var p Priority
+		if err := p.UnmarshalXDR([]byte{0, 0, 0, 1}); err != nil {
+			t.Fatal("Unexpected error", err)
+		}
+		var t2 TrustedStruct
+		if err := t2.UnmarshalXDRInto([]byte{0, 0, 0, 1}, nil); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Error("Expected io.ErrUnexpectedEOF, got", err)
+		}
+
+		// UnmarshalBinary keeps the encoding.BinaryUnmarshaler contract.
+		if err := t2.UnmarshalBinary([]byte{0, 0, 0, 1}); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Error("Expected io.ErrUnexpectedEOF, got", err)
+		}
+		if err := p.UnmarshalBinary([]byte{0, 0, 0, 9}); err == nil {
+			t.Error("Expected an error for an invalid Priority")
+		}
+	}()
+}
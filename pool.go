@@ -0,0 +1,38 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import "sync"
+
+var marshallerPool = sync.Pool{
+	New: func() interface{} {
+		return &Marshaller{}
+	},
+}
+
+// AcquireMarshaller returns an empty Marshaller from a pool. Its buffer may
+// keep the capacity of a previous use, but has zero length, so Grow must be
+// called to make room before marshalling:
+//
+//	m := xdr.AcquireMarshaller()
+//	m.Grow(o.XDRSize())
+//	err := o.MarshalXDRInto(m)
+//	// use m.Data
+//	xdr.ReleaseMarshaller(m)
+func AcquireMarshaller() *Marshaller {
+	return marshallerPool.Get().(*Marshaller)
+}
+
+// ReleaseMarshaller returns m to the pool used by AcquireMarshaller. The
+// buffer is recycled, so m.Data must not be used after the call; copy it
+// first if it needs to be retained.
+func ReleaseMarshaller(m *Marshaller) {
+	m.Data = m.Data[:0]
+	m.Error = nil
+	m.ValidateUTF8 = false
+	m.ByteOrder = nil
+	m.offset = 0
+	marshallerPool.Put(m)
+}
@@ -0,0 +1,263 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"bytes"
+	"io"
+)
+
+// recordChunk is the largest record buffer allocated before its data has
+// actually been read. Longer records grow their buffer as data arrives, so a
+// corrupt length prefix cannot force a huge allocation.
+const recordChunk = 64 << 10
+
+// ReadRecord reads a record prefixed by its length as a 4-byte big-endian
+// unsigned integer and returns its contents, which can be wrapped in an
+// Unmarshaller. If r is at its end before the length, the error is io.EOF;
+// if the record is cut short, it is io.ErrUnexpectedEOF.
+func ReadRecord(r io.Reader) ([]byte, error) {
+	return ReadRecordMax(r, 0)
+}
+
+// ReadRecordMax is like ReadRecord, but fails with an ElementSizeExceeded
+// error, before reading the record, if it is longer than max bytes. A max of
+// zero leaves math.MaxInt32 as the only limit, as described by CheckLength.
+func ReadRecordMax(r io.Reader, max int) ([]byte, error) {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+
+	l, err := CheckLength("record", Uint32BE(hdr[:]), max)
+	if err != nil {
+		return nil, err
+	}
+
+	if l <= recordChunk {
+		buf := make([]byte, l)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return buf, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(recordChunk)
+	n, err := io.CopyN(&buf, r, int64(l))
+	if err == io.EOF && n < int64(l) {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteRecord writes data to w, prefixed by its length as a 4-byte big-endian
+// unsigned integer, in a single Write call. Records longer than
+// math.MaxInt32 bytes, which ReadRecord would reject, fail with an
+// ElementSizeError.
+func WriteRecord(w io.Writer, data []byte) error {
+	if err := checkEncodedLength("record", len(data)); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4+len(data))
+	PutUint32BE(buf, uint32(len(data)))
+	copy(buf[4:], data)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// lastFragment is the bit set in a record marking header for the final
+// fragment of a record.
+const lastFragment = 1 << 31
+
+// defaultFragmentSize is the fragment size used by NewRecordWriter.
+const defaultFragmentSize = 64 << 10
+
+// RecordReader reads records framed with the record marking standard of ONC
+// RPC (RFC 5531). Each record is made of fragments with a 4-byte header whose
+// high bit marks the last fragment and whose low 31 bits give its length.
+// Read returns the contents of the current record, with fragment headers
+// removed, and io.EOF at its end; NextRecord moves on to the next one.
+type RecordReader struct {
+	r        io.Reader
+	left     uint32
+	last     bool
+	inRecord bool
+	err      error
+}
+
+// NewRecordReader returns a RecordReader reading from r.
+func NewRecordReader(r io.Reader) *RecordReader {
+	return &RecordReader{r: r}
+}
+
+// Read reads up to len(p) bytes of the current record. It returns io.EOF
+// once the record is complete. The first call starts the first record.
+func (rr *RecordReader) Read(p []byte) (int, error) {
+	if !rr.inRecord {
+		if err := rr.header(); err != nil {
+			return 0, err
+		}
+	}
+
+	for rr.left == 0 {
+		if rr.last {
+			return 0, io.EOF
+		}
+		if err := rr.header(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+	}
+
+	if uint32(len(p)) > rr.left {
+		p = p[:rr.left]
+	}
+	n, err := rr.r.Read(p)
+	rr.left -= uint32(n)
+	if err == io.EOF && rr.left > 0 {
+		err = io.ErrUnexpectedEOF
+	} else if err == io.EOF {
+		err = nil
+	}
+	if err != nil {
+		rr.err = err
+	}
+	return n, err
+}
+
+// NextRecord skips the rest of the current record, if any, and starts the
+// next one. It returns io.EOF if the stream ends cleanly before it.
+func (rr *RecordReader) NextRecord() error {
+	if rr.inRecord {
+		if _, err := io.Copy(io.Discard, rr); err != nil {
+			return err
+		}
+		rr.inRecord = false
+	}
+	return rr.header()
+}
+
+// header reads the next fragment header.
+func (rr *RecordReader) header() error {
+	if rr.err != nil {
+		return rr.err
+	}
+
+	var hdr [4]byte
+	if _, err := io.ReadFull(rr.r, hdr[:]); err != nil {
+		rr.err = err
+		return err
+	}
+
+	h := Uint32BE(hdr[:])
+	rr.left = h &^ lastFragment
+	rr.last = h&lastFragment != 0
+	rr.inRecord = true
+	return nil
+}
+
+// RecordWriter writes records framed with the record marking standard of ONC
+// RPC (RFC 5531). Data written is buffered and sent as fragments of at most
+// the writer's fragment size; EndRecord sends the last fragment of the
+// current record.
+type RecordWriter struct {
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewRecordWriter returns a RecordWriter writing to w, with a fragment size
+// of 64 KiB.
+func NewRecordWriter(w io.Writer) *RecordWriter {
+	return NewRecordWriterSize(w, defaultFragmentSize)
+}
+
+// NewRecordWriterSize returns a RecordWriter writing to w, with fragments of
+// at most size bytes. It panics if size is not positive or exceeds the 31
+// bits available for a fragment length.
+func NewRecordWriterSize(w io.Writer, size int) *RecordWriter {
+	if size <= 0 || uint64(size) >= lastFragment {
+		panic("xdr: invalid fragment size")
+	}
+	return &RecordWriter{w: w, buf: make([]byte, 4, 4+size)}
+}
+
+// Write appends p to the current record, sending full fragments as needed.
+func (rw *RecordWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		if rw.err != nil {
+			return n, rw.err
+		}
+		if len(rw.buf) == cap(rw.buf) {
+			rw.flush(false)
+			continue
+		}
+		c := copy(rw.buf[len(rw.buf):cap(rw.buf)], p)
+		rw.buf = rw.buf[:len(rw.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, rw.err
+}
+
+// EndRecord sends the buffered data as the last fragment of the current
+// record. The next Write starts a new record.
+func (rw *RecordWriter) EndRecord() error {
+	rw.flush(true)
+	return rw.err
+}
+
+// flush sends the buffered data as a fragment.
+func (rw *RecordWriter) flush(last bool) {
+	if rw.err != nil {
+		return
+	}
+
+	h := uint32(len(rw.buf) - 4)
+	if last {
+		h |= lastFragment
+	}
+	PutUint32BE(rw.buf, h)
+
+	_, rw.err = rw.w.Write(rw.buf)
+	rw.buf = rw.buf[:4]
+}
+
+// RecordEncoder is an Encoder whose output is framed as records with the
+// record marking standard, as by a RecordWriter, so that a stream of messages
+// is sent by encoding each one and calling NextRecord. Flush only moves the
+// encoded data into the current record; NextRecord sends it.
+type RecordEncoder struct {
+	*Encoder
+	rw *RecordWriter
+}
+
+// NewRecordEncoder returns a RecordEncoder writing to w, with a fragment
+// size of 64 KiB.
+func NewRecordEncoder(w io.Writer) *RecordEncoder {
+	rw := NewRecordWriter(w)
+	return &RecordEncoder{Encoder: NewEncoder(rw), rw: rw}
+}
+
+// NextRecord ends the message encoded since the previous call, sending it as
+// the last fragment of its record, and starts the next one.
+func (e *RecordEncoder) NextRecord() error {
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	return e.rw.EndRecord()
+}
@@ -0,0 +1,190 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"dario.cat/xdr"
+)
+
+func TestRecord(t *testing.T) {
+	s0 := OtherStruct{F1: 42, F2: "hello"}
+	big := bytes.Repeat([]byte{0xa5}, 200<<10)
+
+	c0, c1 := net.Pipe()
+	go func() {
+		xdr.WriteRecord(c0, s0.MustMarshalXDR())
+		xdr.WriteRecord(c0, nil)
+		xdr.WriteRecord(c0, big)
+		c0.Close()
+	}()
+
+	bs, err := xdr.ReadRecord(c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s1 OtherStruct
+	u := &xdr.Unmarshaller{Data: bs}
+	s1.UnmarshalXDRFrom(u)
+	if err := u.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if s1 != s0 {
+		t.Errorf("%+v != %+v", s1, s0)
+	}
+
+	if bs, err := xdr.ReadRecord(c1); err != nil || len(bs) != 0 {
+		t.Fatal("Expected empty record, got", bs, err)
+	}
+	if bs, err := xdr.ReadRecord(c1); err != nil || !bytes.Equal(bs, big) {
+		t.Fatal("Large record mismatch", len(bs), err)
+	}
+	if _, err := xdr.ReadRecord(c1); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+}
+
+func TestReadRecordErrors(t *testing.T) {
+	var buf bytes.Buffer
+	xdr.WriteRecord(&buf, []byte("hello, world"))
+	bs := buf.Bytes()
+
+	if _, err := xdr.ReadRecordMax(bytes.NewReader(bs), 8); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Fatal("Expected xdr.ErrElementSizeExceeded, got", err)
+	}
+	if _, err := xdr.ReadRecordMax(bytes.NewReader(bs), 12); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if _, err := xdr.ReadRecord(bytes.NewReader(bs[:2])); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if _, err := xdr.ReadRecord(bytes.NewReader(bs[:10])); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	// A huge length with little data behind it must not allocate it all.
+	huge := []byte{0x7f, 0xff, 0xff, 0xff, 1, 2, 3, 4}
+	if _, err := xdr.ReadRecord(bytes.NewReader(huge)); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestRecordMarking(t *testing.T) {
+	var buf bytes.Buffer
+	rw := xdr.NewRecordWriterSize(&buf, 8)
+
+	e := xdr.NewEncoder(rw)
+	s0 := OtherStruct{F1: 42, F2: "hello, world"}
+	s0.EncodeXDR(e)
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.EndRecord(); err != nil {
+		t.Fatal(err)
+	}
+	rw.Write([]byte("second"))
+	rw.EndRecord()
+
+	// 20 bytes in three fragments, then 6 bytes in one.
+	if l := buf.Len(); l != 3*4+20+4+6 {
+		t.Fatal("Unexpected length", l)
+	}
+	if h := buf.Bytes()[:4]; !bytes.Equal(h, []byte{0, 0, 0, 8}) {
+		t.Fatalf("Unexpected first header %x", h)
+	}
+
+	rr := xdr.NewRecordReader(&buf)
+	bs, err := io.ReadAll(rr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s1 OtherStruct
+	if err := s1.UnmarshalXDR(bs); err != nil {
+		t.Fatal(err)
+	}
+	if s1 != s0 {
+		t.Errorf("%+v != %+v", s1, s0)
+	}
+
+	if err := rr.NextRecord(); err != nil {
+		t.Fatal(err)
+	}
+	if bs, err := io.ReadAll(rr); err != nil || string(bs) != "second" {
+		t.Fatalf("Unexpected second record %q, %v", bs, err)
+	}
+	if err := rr.NextRecord(); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+}
+
+func TestRecordEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	e := xdr.NewRecordEncoder(&buf)
+	msgs := []OtherStruct{{F1: 1, F2: "one"}, {F1: 2, F2: "two"}}
+	for _, s := range msgs {
+		s.EncodeXDR(e.Encoder)
+		if err := e.NextRecord(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each message is a record of a single fragment.
+	if h := buf.Bytes()[:4]; !bytes.Equal(h, []byte{0x80, 0, 0, 12}) {
+		t.Fatalf("Unexpected first header %x", h)
+	}
+
+	rr := xdr.NewRecordReader(&buf)
+	for _, s0 := range msgs {
+		if err := rr.NextRecord(); err != nil {
+			t.Fatal(err)
+		}
+		bs, err := io.ReadAll(rr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var s1 OtherStruct
+		if err := s1.UnmarshalXDR(bs); err != nil {
+			t.Fatal(err)
+		}
+		if s1 != s0 {
+			t.Errorf("%+v != %+v", s1, s0)
+		}
+	}
+	if err := rr.NextRecord(); err != io.EOF {
+		t.Fatal("Expected io.EOF, got", err)
+	}
+}
+
+func TestRecordReaderErrors(t *testing.T) {
+	// Non-last fragment and nothing after it.
+	data := []byte{0, 0, 0, 2, 'a', 'b'}
+	if _, err := io.ReadAll(xdr.NewRecordReader(bytes.NewReader(data))); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	// Last fragment cut short.
+	data = []byte{0x80, 0, 0, 4, 'a', 'b'}
+	if _, err := io.ReadAll(xdr.NewRecordReader(bytes.NewReader(data))); err != io.ErrUnexpectedEOF {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+
+	// Skipping an unread record.
+	data = []byte{0x80, 0, 0, 2, 'a', 'b', 0x80, 0, 0, 1, 'c'}
+	rr := xdr.NewRecordReader(bytes.NewReader(data))
+	if err := rr.NextRecord(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rr.NextRecord(); err != nil {
+		t.Fatal(err)
+	}
+	if bs, err := io.ReadAll(rr); err != nil || string(bs) != "c" {
+		t.Fatalf("Unexpected record %q, %v", bs, err)
+	}
+}
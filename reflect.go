@@ -0,0 +1,476 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// xdrMarshaler is implemented by types that encode themselves, such as the
+// ones generated by genxdr.
+type xdrMarshaler interface {
+	Sizer
+	MarshalXDRInto(m *Marshaller) error
+}
+
+// xdrUnmarshaler is implemented by types that decode themselves, such as the
+// ones generated by genxdr.
+type xdrUnmarshaler interface {
+	UnmarshalXDRFrom(u *Unmarshaller) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*xdrMarshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*xdrUnmarshaler)(nil)).Elem()
+	timeType        = reflect.TypeOf(time.Time{})
+)
+
+// Marshal returns the XDR encoding of v, using reflection instead of
+// generated code. Struct fields are encoded in declaration order, following
+// the same rules as genxdr: int and uint are encoded as hypers, slices and
+// strings carry a size prefix, arrays are encoded without one and pointers
+// are encoded as optional data. Unexported fields are ignored, and time.Time
+// values are encoded as by Marshaller.MarshalTime.
+//
+// A field tagged `xdr:"-"` is skipped, and `xdr:"max=N"` limits the length
+// of a string, byte slice or slice field. Values implementing XDRSize and
+// MarshalXDRInto encode themselves.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("xdr: cannot marshal nil")
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("xdr: cannot marshal nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	} else {
+		// Work on an addressable copy, so that pointer receivers are found.
+		c := reflect.New(rv.Type()).Elem()
+		c.Set(rv)
+		rv = c
+	}
+
+	m := &Marshaller{}
+	if err := marshalReflect(m, rv, "value", 0); err != nil {
+		return nil, err
+	}
+	if m.Error != nil {
+		return nil, m.Error
+	}
+
+	return m.Data[:m.offset], nil
+}
+
+// Unmarshal parses the XDR-encoded data and stores the result in the value
+// pointed to by v, using reflection instead of generated code. The encoding
+// rules and struct tags are the same as for Marshal. Values implementing
+// UnmarshalXDRFrom decode themselves.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("xdr: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+
+	u := &Unmarshaller{Data: data}
+	unmarshalReflect(u, rv.Elem(), "value", 0)
+	return u.Error
+}
+
+// Validate reports whether data is the complete XDR encoding of a value of
+// the type of v, following the same rules as Unmarshal, without building the
+// value: only the type of v is used, and it may be given as a nil pointer.
+// Every size prefix is checked against the data and the field's max tag,
+// data must be a whole number of four byte units and trailing data is an
+// error. Decoding is strict, so that non-canonical padding, booleans and
+// small integers are rejected too.
+//
+// Strings and byte slices are checked in place, so that, unlike Unmarshal,
+// Validate does not allocate for them. Values implementing UnmarshalXDRFrom
+// are decoded into a scratch value, as their encoding is known to them only.
+func Validate(data []byte, v interface{}) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return fmt.Errorf("xdr: cannot validate against nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	u, err := NewStrictUnmarshaller(data)
+	if err != nil {
+		return err
+	}
+	validateReflect(u, t, "value", 0)
+	return u.Finish()
+}
+
+func marshalReflect(m *Marshaller, v reflect.Value, name string, max int) error {
+	if v.Kind() != reflect.Ptr && v.Addr().Type().Implements(marshalerType) {
+		mv := v.Addr().Interface().(xdrMarshaler)
+		m.Grow(mv.XDRSize())
+		return mv.MarshalXDRInto(m)
+	}
+	if v.Type() == timeType {
+		m.Grow(12)
+		m.MarshalTime(v.Interface().(time.Time))
+		return m.Error
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		m.Grow(4)
+		m.MarshalBool(v.Bool())
+	case reflect.Int8:
+		m.Grow(4)
+		m.MarshalInt8(int8(v.Int()))
+	case reflect.Int16:
+		m.Grow(4)
+		m.MarshalInt16(int16(v.Int()))
+	case reflect.Int32:
+		m.Grow(4)
+		m.MarshalInt32(int32(v.Int()))
+	case reflect.Int, reflect.Int64:
+		m.Grow(8)
+		m.MarshalInt64(v.Int())
+	case reflect.Uint8:
+		m.Grow(4)
+		m.MarshalUint8(uint8(v.Uint()))
+	case reflect.Uint16:
+		m.Grow(4)
+		m.MarshalUint16(uint16(v.Uint()))
+	case reflect.Uint32:
+		m.Grow(4)
+		m.MarshalUint32(uint32(v.Uint()))
+	case reflect.Uint, reflect.Uint64:
+		m.Grow(8)
+		m.MarshalUint64(v.Uint())
+	case reflect.Float32:
+		m.Grow(4)
+		m.MarshalFloat32(float32(v.Float()))
+	case reflect.Float64:
+		m.Grow(8)
+		m.MarshalFloat64(v.Float())
+
+	case reflect.String:
+		s := v.String()
+		if max > 0 && len(s) > max {
+			return ElementSizeExceeded(name, len(s), max)
+		}
+		m.Grow(StringSize(s))
+		m.MarshalString(s)
+
+	case reflect.Slice:
+		if max > 0 && v.Len() > max {
+			return ElementSizeExceeded(name, v.Len(), max)
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			m.Grow(BytesSize(v.Len()))
+			m.MarshalBytes(v.Bytes())
+			break
+		}
+		m.Grow(4)
+		m.MarshalUint32(uint32(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if err := marshalReflect(m, v.Index(i), name, 0); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			m.Grow(v.Len() + Padding(v.Len()))
+			m.MarshalFixedOpaque(v.Slice(0, v.Len()).Bytes())
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := marshalReflect(m, v.Index(i), name, 0); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Ptr:
+		m.Grow(4)
+		m.MarshalBool(!v.IsNil())
+		if !v.IsNil() {
+			return marshalReflect(m, v.Elem(), name, max)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			ft, err := parseTag(f)
+			if err != nil {
+				return err
+			}
+			if ft.Skip {
+				continue
+			}
+			if err := marshalReflect(m, v.Field(i), f.Name, ft.Max); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("xdr: unsupported type %s", v.Type())
+	}
+
+	return m.Error
+}
+
+func unmarshalReflect(u *Unmarshaller, v reflect.Value, name string, max int) {
+	if u.Error != nil {
+		return
+	}
+	if v.Kind() != reflect.Ptr && v.Addr().Type().Implements(unmarshalerType) {
+		if err := v.Addr().Interface().(xdrUnmarshaler).UnmarshalXDRFrom(u); err != nil {
+			u.Fail(err)
+		}
+		return
+	}
+	if v.Type() == timeType {
+		v.Set(reflect.ValueOf(u.UnmarshalTime()))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(u.UnmarshalBool())
+	case reflect.Int8:
+		v.SetInt(int64(int8(u.UnmarshalUint8())))
+	case reflect.Int16:
+		v.SetInt(int64(int16(u.UnmarshalUint16())))
+	case reflect.Int32:
+		v.SetInt(int64(u.UnmarshalInt32()))
+	case reflect.Int, reflect.Int64:
+		v.SetInt(u.UnmarshalInt64())
+	case reflect.Uint8:
+		v.SetUint(uint64(u.UnmarshalUint8()))
+	case reflect.Uint16:
+		v.SetUint(uint64(u.UnmarshalUint16()))
+	case reflect.Uint32:
+		v.SetUint(uint64(u.UnmarshalUint32()))
+	case reflect.Uint, reflect.Uint64:
+		v.SetUint(u.UnmarshalUint64())
+	case reflect.Float32:
+		v.SetFloat(float64(u.UnmarshalFloat32()))
+	case reflect.Float64:
+		v.SetFloat(u.UnmarshalFloat64())
+
+	case reflect.String:
+		v.SetString(u.UnmarshalStringMax(max))
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(u.UnmarshalBytesCopyMax(max))
+			break
+		}
+		n := u.UnmarshalLength(name, max)
+		if u.Error != nil {
+			return
+		}
+		if n == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			break
+		}
+		if !u.Require(n, minSize(v.Type().Elem())) {
+			return
+		}
+		if !u.Alloc(n * int(v.Type().Elem().Size())) {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			unmarshalReflect(u, s.Index(i), name, 0)
+		}
+		v.Set(s)
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(v, reflect.ValueOf(u.UnmarshalFixedOpaque(v.Len())))
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			unmarshalReflect(u, v.Index(i), name, 0)
+		}
+
+	case reflect.Ptr:
+		if !u.UnmarshalBool() {
+			v.Set(reflect.Zero(v.Type()))
+			break
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		unmarshalReflect(u, v.Elem(), name, max)
+
+	case reflect.Struct:
+		if !u.Enter() {
+			return
+		}
+		defer u.Leave()
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			ft, err := parseTag(f)
+			if err != nil {
+				u.Fail(err)
+				return
+			}
+			if ft.Skip {
+				continue
+			}
+			unmarshalReflect(u, v.Field(i), f.Name, ft.Max)
+		}
+
+	default:
+		u.Fail(fmt.Errorf("xdr: unsupported type %s", v.Type()))
+	}
+}
+
+// validateReflect consumes the encoding of a value of type t, checking it as
+// unmarshalReflect would without storing it.
+func validateReflect(u *Unmarshaller, t reflect.Type, name string, max int) {
+	if u.Error != nil {
+		return
+	}
+	if t.Kind() != reflect.Ptr && reflect.PointerTo(t).Implements(unmarshalerType) {
+		if err := reflect.New(t).Interface().(xdrUnmarshaler).UnmarshalXDRFrom(u); err != nil {
+			u.Fail(err)
+		}
+		return
+	}
+	if t == timeType {
+		u.UnmarshalTime()
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		u.UnmarshalBool()
+	case reflect.Int8, reflect.Uint8:
+		u.UnmarshalUint8()
+	case reflect.Int16, reflect.Uint16:
+		u.UnmarshalUint16()
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		u.UnmarshalUint32()
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+		u.UnmarshalUint64()
+
+	case reflect.String:
+		u.UnmarshalBytesMax(max)
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			u.UnmarshalBytesMax(max)
+			break
+		}
+		n := u.UnmarshalLength(name, max)
+		if u.Error != nil {
+			return
+		}
+		if !u.Require(n, minSize(t.Elem())) {
+			return
+		}
+		for i := 0; i < n && u.Error == nil; i++ {
+			validateReflect(u, t.Elem(), name, 0)
+		}
+
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			u.UnmarshalFixedOpaque(t.Len())
+			break
+		}
+		for i := 0; i < t.Len() && u.Error == nil; i++ {
+			validateReflect(u, t.Elem(), name, 0)
+		}
+
+	case reflect.Ptr:
+		if u.UnmarshalBool() {
+			validateReflect(u, t.Elem(), name, max)
+		}
+
+	case reflect.Struct:
+		if !u.Enter() {
+			return
+		}
+		defer u.Leave()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			ft, err := parseTag(f)
+			if err != nil {
+				u.Fail(err)
+				return
+			}
+			if ft.Skip {
+				continue
+			}
+			validateReflect(u, f.Type, f.Name, ft.Max)
+		}
+
+	default:
+		u.Fail(fmt.Errorf("xdr: unsupported type %s", t))
+	}
+}
+
+// minSize returns the smallest encoded size of a value of type t, or zero if
+// it is not known.
+func minSize(t reflect.Type) int {
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return 0
+	}
+	if t == timeType {
+		return 12
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Float32:
+		return 4
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64:
+		return 8
+	case reflect.String, reflect.Slice, reflect.Ptr:
+		return 4
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return t.Len() + Padding(t.Len())
+		}
+		return t.Len() * minSize(t.Elem())
+	case reflect.Struct:
+		l := 0
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if ft, err := parseTag(f); f.PkgPath != "" || ft.Skip || err != nil {
+				continue
+			}
+			l += minSize(f.Type)
+		}
+		return l
+	}
+
+	return 0
+}
+
+// parseTag parses the xdr struct tag of f.
+func parseTag(f reflect.StructField) (FieldTag, error) {
+	ft, err := ParseFieldTag(f.Tag.Get("xdr"))
+	if err != nil {
+		return FieldTag{}, fmt.Errorf("%w on field %s", err, f.Name)
+	}
+	return ft, nil
+}
@@ -0,0 +1,276 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"dario.cat/xdr"
+)
+
+// Same layout as TestStruct, but without generated methods
+type reflectStruct struct {
+	B    bool
+	I    int
+	I8   int8
+	UI8  uint8
+	I16  int16
+	UI16 uint16
+	I32  int32
+	UI32 uint32
+	I64  int64
+	UI64 uint64
+	BS   []byte `xdr:"max=1024"`
+	S    string `xdr:"max=1024"`
+	C    Opaque
+	SS   []string `xdr:"max=1024"`
+	ES   EmptyStruct
+	OS   OtherStruct
+	OSs  []OtherStruct
+}
+
+func TestReflectMatchesGenerated(t *testing.T) {
+	fn := func(t0 TestStruct) bool {
+		bs, err := t0.MarshalXDR()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rs := reflectStruct(t0)
+		rbs, err := xdr.Marshal(rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(bs, rbs) {
+			t.Logf("generated %x", bs)
+			t.Logf("reflected %x", rbs)
+			return false
+		}
+
+		var r1 reflectStruct
+		if err := xdr.Unmarshal(bs, &r1); err != nil {
+			t.Fatal(err)
+		}
+		return t0.TestEquals(TestStruct(r1))
+	}
+	if err := quick.Check(fn, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReflectTags(t *testing.T) {
+	type tagged struct {
+		A      uint32
+		Skip   string `xdr:"-"`
+		Name   string `xdr:"max=4"`
+		hidden uint32
+		Opt    *uint64
+		Fixed  [3]uint16
+	}
+
+	v0 := tagged{A: 1, Skip: "skipped", Name: "abcd", hidden: 2, Fixed: [3]uint16{3, 4, 5}}
+	bs, err := xdr.Marshal(&v0)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	// A, Name (prefix + 4 bytes), Opt flag, three Fixed elements
+	if len(bs) != 4+8+4+12 {
+		t.Errorf("Expected %d bytes, got %d", 4+8+4+12, len(bs))
+	}
+
+	var v1 tagged
+	if err := xdr.Unmarshal(bs, &v1); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	v0.Skip, v0.hidden = "", 0
+	if !reflect.DeepEqual(v0, v1) {
+		t.Errorf("Expected %+v, got %+v", v0, v1)
+	}
+
+	if _, err := xdr.Marshal(tagged{Name: "abcde"}); err == nil {
+		t.Error("Expected error for oversized string")
+	}
+	long, _ := xdr.Marshal(struct{ S string }{"abcde"})
+	if err := xdr.Unmarshal(append([]byte{0, 0, 0, 0}, long...), &v1); err == nil {
+		t.Error("Expected error for oversized string")
+	}
+}
+
+func TestReflectErrors(t *testing.T) {
+	if _, err := xdr.Marshal(struct{ C chan int }{}); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+	if _, err := xdr.Marshal(struct {
+		S string `xdr:"size=1"`
+	}{}); err == nil {
+		t.Error("Expected error for invalid tag")
+	}
+
+	var v struct{ S []uint32 }
+	if err := xdr.Unmarshal(nil, v); err == nil {
+		t.Error("Expected error for non-pointer")
+	}
+	if err := xdr.Unmarshal([]byte{0x7f, 0xff, 0xff, 0xf}, &v); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal("Expected io.ErrUnexpectedEOF, got", err)
+	}
+}
+
+func TestReflectTime(t *testing.T) {
+	type event struct {
+		At   time.Time
+		Name string
+	}
+	e0 := event{At: time.Date(2024, 2, 29, 12, 34, 56, 789, time.UTC), Name: "leap"}
+
+	bs, err := xdr.Marshal(e0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := xdr.NewMarshallerSize(12 + xdr.StringSize(e0.Name))
+	m.MarshalTime(e0.At)
+	m.MarshalString(e0.Name)
+	if !bytes.Equal(bs, m.Data) {
+		t.Fatalf("Unexpected encoding\n%s", xdr.Dump(bs))
+	}
+
+	var e1 event
+	if err := xdr.Unmarshal(bs, &e1); err != nil {
+		t.Fatal(err)
+	}
+	if !e1.At.Equal(e0.At) || e1.Name != e0.Name {
+		t.Errorf("%+v != %+v", e1, e0)
+	}
+}
+
+type reflectChain struct {
+	Next *reflectChain
+}
+
+func TestReflectMaxDepth(t *testing.T) {
+	bs := append(bytes.Repeat([]byte{0, 0, 0, 1}, 1<<16), 0, 0, 0, 0)
+	var c reflectChain
+	if err := xdr.Unmarshal(bs, &c); err != xdr.ErrMaxDepth {
+		t.Fatal("Expected ErrMaxDepth, got", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rs := reflectStruct{S: "string", BS: []byte{1, 2, 3}, SS: []string{"a", "b"}, OSs: []OtherStruct{{F1: 1}}}
+	bs, err := xdr.Marshal(rs)
+	if err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if err := xdr.Validate(bs, (*reflectStruct)(nil)); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+	if err := xdr.Validate(bs, reflectStruct{}); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	// Checking the encoding in place allocates nothing for its strings.
+	type strs struct {
+		S  string
+		BS []byte
+		SS []string
+	}
+	bs1, _ := xdr.Marshal(strs{S: "string", BS: []byte{1}, SS: []string{"a", "b", "c"}})
+	if n := testing.AllocsPerRun(100, func() {
+		xdr.Validate(bs1, (*strs)(nil))
+	}); n > 1 {
+		t.Errorf("Expected at most 1 allocation, got %v", n)
+	}
+
+	// Generated types validate through their own decoding.
+	o := OtherStruct{F1: 2, F2: "x"}
+	if err := xdr.Validate(o.MustMarshalXDR(), &o); err != nil {
+		t.Fatal("Unexpected error", err)
+	}
+
+	if err := xdr.Validate(append(bs, 0, 0, 0, 0), rs); !errors.Is(err, xdr.ErrTrailingData) {
+		t.Error("Expected ErrTrailingData, got", err)
+	}
+	if err := xdr.Validate(bs[:len(bs)-4], rs); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Error("Expected io.ErrUnexpectedEOF, got", err)
+	}
+	if err := xdr.Validate(bs[:len(bs)-1], rs); !errors.Is(err, xdr.ErrUnalignedData) {
+		t.Error("Expected ErrUnalignedData, got", err)
+	}
+	if err := xdr.Validate([]byte{0, 0, 0, 2}, struct{ B bool }{}); err != xdr.ErrInvalidBool {
+		t.Error("Expected ErrInvalidBool, got", err)
+	}
+	if err := xdr.Validate([]byte{0, 0, 0, 5, 'a', 'b', 'c', 'd', 'e', 0, 0, 0}, struct {
+		S string `xdr:"max=4"`
+	}{}); !errors.Is(err, xdr.ErrElementSizeExceeded) {
+		t.Error("Expected ErrElementSizeExceeded, got", err)
+	}
+	if err := xdr.Validate(nil, nil); err == nil {
+		t.Error("Expected error for nil schema")
+	}
+}
+
+type describedInner struct {
+	N uint32
+	C *describedInner
+}
+
+type describedStruct struct {
+	Name    string `xdr:"max=64"`
+	Size    int
+	Delta   int16
+	Hash    [32]byte
+	Data    []byte `xdr:"max=16"`
+	Inner   describedInner
+	Inners  []describedInner
+	Other   OtherStruct
+	When    time.Time
+	Lists   [][]uint32
+	Skipped bool `xdr:"-"`
+	hidden  bool
+}
+
+func TestDescribeType(t *testing.T) {
+	expected := `struct describedStruct {
+	string Name<64>;
+	hyper Size;
+	unsigned int Delta;
+	opaque Hash[32];
+	opaque Data<16>;
+	describedInner Inner;
+	describedInner Inners<>;
+	OtherStruct Other;
+	struct { hyper seconds; unsigned int nseconds; } When;
+	struct { unsigned int value<>; } Lists<>;
+}
+
+struct describedInner {
+	unsigned int N;
+	describedInner *C;
+}
+
+struct OtherStruct {
+	unsigned int F1;
+	string F2<>;
+}
+`
+	if d := xdr.DescribeType(&describedStruct{}); d != expected {
+		t.Errorf("Incorrect description\n%s", d)
+	}
+	// Generated types are described by their fields like any other.
+	if d := xdr.DescribeType(OtherStruct{}); d != "struct OtherStruct {\n\tunsigned int F1;\n\tstring F2<>;\n}\n" {
+		t.Errorf("Incorrect description\n%s", d)
+	}
+	if d := xdr.DescribeType([]string{}); d != "typedef struct { string value<>; } value<>;\n" {
+		t.Errorf("Incorrect description\n%s", d)
+	}
+	if d := xdr.DescribeType(nil); d != "" {
+		t.Errorf("Incorrect description\n%s", d)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RoundTrip marshals v, unmarshals the result into a new value of the same
+// type and marshals that again, returning an error unless all of the data
+// was consumed and both encodings are identical. It works with the types
+// generated by genxdr and is meant for tests and fuzzing of downstream
+// schemas:
+//
+//	func FuzzRecord(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, id uint64, name string) {
+//			if err := xdr.RoundTrip(Record{ID: id, Name: name}); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+//
+// Values that cannot be marshalled, for example because a field exceeds its
+// size limit, return the marshalling error.
+func RoundTrip[T interface{ MarshalXDR() ([]byte, error) }, P interface {
+	*T
+	UnmarshalXDRFrom(u *Unmarshaller) error
+}](v T) error {
+	bs, err := v.MarshalXDR()
+	if err != nil {
+		return err
+	}
+
+	var v1 T
+	u := &Unmarshaller{Data: bs}
+	if err := P(&v1).UnmarshalXDRFrom(u); err != nil {
+		return fmt.Errorf("xdr: round trip unmarshal: %w", err)
+	}
+	if err := u.Finish(); err != nil {
+		return fmt.Errorf("xdr: round trip unmarshal: %w", err)
+	}
+
+	bs1, err := v1.MarshalXDR()
+	if err != nil {
+		return fmt.Errorf("xdr: round trip remarshal: %w", err)
+	}
+	if !bytes.Equal(bs, bs1) {
+		return fmt.Errorf("xdr: round trip mismatch:\n%s\n!=\n%s", Dump(bs), Dump(bs1))
+	}
+
+	return nil
+}
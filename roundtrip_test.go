@@ -0,0 +1,47 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr_test
+
+import (
+	"testing"
+	"testing/quick"
+
+	"dario.cat/xdr"
+)
+
+func TestRoundTrip(t *testing.T) {
+	fn := func(t0 TestStruct) bool {
+		if err := xdr.RoundTrip(t0); err != nil {
+			t.Log(err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(fn, nil); err != nil {
+		t.Error(err)
+	}
+
+	if err := xdr.RoundTrip(Result{Code: StatusFailed, Value: &Failure{Reason: "x"}}); err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if err := xdr.RoundTrip(TaggedStruct{Name: "too long name"}); err == nil {
+		t.Error("Expected error for oversized Name")
+	}
+
+	// Scratch is not encoded, but MarshalXDR ignores it too, so the
+	// encodings still match.
+	if err := xdr.RoundTrip(TaggedStruct{Scratch: 1}); err != nil {
+		t.Error("Unexpected error", err)
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(uint32(1), "seed")
+	f.Fuzz(func(t *testing.T, f1 uint32, f2 string) {
+		if err := xdr.RoundTrip(OtherStruct{F1: f1, F2: f2}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
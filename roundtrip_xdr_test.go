@@ -0,0 +1,331 @@
+// ************************************************************
+// This file is automatically generated by genxdr. Do not edit.
+// ************************************************************
+
+package xdr_test
+
+import (
+	"testing"
+
+	"dario.cat/xdr"
+)
+
+func TestXDRRoundTripStatus(t *testing.T) {
+	if err := xdr.RoundTrip(StatusOK); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalStatus(f *testing.F) {
+	if bs, err := (StatusOK).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Status
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripTestStruct(t *testing.T) {
+	if err := xdr.RoundTrip(TestStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalTestStruct(f *testing.F) {
+	if bs, err := (TestStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o TestStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripEmptyStruct(t *testing.T) {
+	if err := xdr.RoundTrip(EmptyStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalEmptyStruct(f *testing.F) {
+	if bs, err := (EmptyStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o EmptyStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripOtherStruct(t *testing.T) {
+	if err := xdr.RoundTrip(OtherStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalOtherStruct(f *testing.F) {
+	if bs, err := (OtherStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o OtherStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripStringsStruct(t *testing.T) {
+	if err := xdr.RoundTrip(StringsStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalStringsStruct(f *testing.F) {
+	if bs, err := (StringsStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o StringsStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripBatch(t *testing.T) {
+	if err := xdr.RoundTrip(Batch{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalBatch(f *testing.F) {
+	if bs, err := (Batch{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Batch
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripItem(t *testing.T) {
+	if err := xdr.RoundTrip(Item{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalItem(f *testing.F) {
+	if bs, err := (Item{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Item
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripEnumStruct(t *testing.T) {
+	if err := xdr.RoundTrip(EnumStruct{S: StatusOK}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalEnumStruct(f *testing.F) {
+	if bs, err := (EnumStruct{S: StatusOK}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o EnumStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripResult(t *testing.T) {
+	if err := xdr.RoundTrip(Result{Code: StatusOK, Value: &OtherStruct{}}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalResult(f *testing.F) {
+	if bs, err := (Result{Code: StatusOK, Value: &OtherStruct{}}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Result
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripFailure(t *testing.T) {
+	if err := xdr.RoundTrip(Failure{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalFailure(f *testing.F) {
+	if bs, err := (Failure{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Failure
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripOptionalStruct(t *testing.T) {
+	if err := xdr.RoundTrip(OptionalStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalOptionalStruct(f *testing.F) {
+	if bs, err := (OptionalStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o OptionalStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripTaggedStruct(t *testing.T) {
+	if err := xdr.RoundTrip(TaggedStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalTaggedStruct(f *testing.F) {
+	if bs, err := (TaggedStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o TaggedStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripMapStruct(t *testing.T) {
+	if err := xdr.RoundTrip(MapStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalMapStruct(f *testing.F) {
+	if bs, err := (MapStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o MapStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripFloatStruct(t *testing.T) {
+	if err := xdr.RoundTrip(FloatStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalFloatStruct(f *testing.F) {
+	if bs, err := (FloatStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o FloatStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripChain(t *testing.T) {
+	if err := xdr.RoundTrip(Chain{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalChain(f *testing.F) {
+	if bs, err := (Chain{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Chain
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripHashStruct(t *testing.T) {
+	if err := xdr.RoundTrip(HashStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalHashStruct(f *testing.F) {
+	if bs, err := (HashStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o HashStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripNamedStruct(t *testing.T) {
+	if err := xdr.RoundTrip(NamedStruct{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalNamedStruct(f *testing.F) {
+	if bs, err := (NamedStruct{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o NamedStruct
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripRecordV1(t *testing.T) {
+	if err := xdr.RoundTrip(RecordV1{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalRecordV1(f *testing.F) {
+	if bs, err := (RecordV1{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o RecordV1
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripRecordV2(t *testing.T) {
+	if err := xdr.RoundTrip(RecordV2{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalRecordV2(f *testing.F) {
+	if bs, err := (RecordV2{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o RecordV2
+		o.UnmarshalXDR(data)
+	})
+}
+
+func TestXDRRoundTripEnvelope(t *testing.T) {
+	if err := xdr.RoundTrip(Envelope{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzUnmarshalEnvelope(f *testing.F) {
+	if bs, err := (Envelope{}).MarshalXDR(); err == nil {
+		f.Add(bs)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var o Envelope
+		o.UnmarshalXDR(data)
+	})
+}
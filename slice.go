@@ -0,0 +1,58 @@
+// Copyright (C) 2014 Jakob Borg. All rights reserved.
+// Copyright (C) 2018 Dario Castañé. All rights reserved. Use of this source code
+// is governed by an MIT-style license that can be found in the LICENSE file.
+
+package xdr
+
+import "unsafe"
+
+// MarshalSlice writes the number of elements in s, followed by each element
+// as encoded by enc. This is the encoding of XDR variable-length arrays.
+func MarshalSlice[T any](m *Marshaller, s []T, enc func(*Marshaller, T)) {
+	m.MarshalUint32(uint32(len(s)))
+	for _, v := range s {
+		if m.Error != nil {
+			return
+		}
+		enc(m, v)
+	}
+}
+
+// UnmarshalSlice reads a number of elements followed by each element as
+// decoded by dec. If max is positive, larger counts are rejected with an
+// ElementSizeExceeded error. As every XDR item but void takes at least four
+// bytes, counts the remaining data cannot hold are rejected too, so that no
+// memory is allocated for them.
+func UnmarshalSlice[T any](u *Unmarshaller, dec func(*Unmarshaller) T, max int) []T {
+	l := u.unmarshalCount(max, 4)
+	if l == 0 {
+		return nil
+	}
+
+	var zero T
+	if !u.Alloc(l * int(unsafe.Sizeof(zero))) {
+		return nil
+	}
+
+	s := make([]T, l)
+	for i := range s {
+		s[i] = dec(u)
+		if u.Error != nil {
+			return nil
+		}
+	}
+
+	return s
+}
+
+// SliceXDRSize returns the XDR encoded size of s as a variable-length array:
+// the number of elements followed by each element, as sized by its XDRSize
+// method. It allows checking whether a batch fits in a frame before
+// marshalling it.
+func SliceXDRSize[T interface{ XDRSize() int }](s []T) int {
+	l := 4
+	for i := range s {
+		l += s[i].XDRSize()
+	}
+	return l
+}
@@ -4,15 +4,354 @@
 
 package xdr
 
-import "io"
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
 
 // Unmarshaller is a thin wrapper around a byte buffer. The Unmarshal... methods
 // don't individually return an error - the intention is that multiple fields are
 // unmarshalled in rapid succession, followed by a check of the Error field on
-// the Unmarshaller.
+// the Unmarshaller. Errors caused by a short buffer wrap io.ErrUnexpectedEOF
+// and report the offset at which the data ran out.
+//
+// When Strict is set, the Unmarshaller additionally rejects encodings that
+// RFC 4506 does not allow, such as non-zero padding bytes, and uint8 or
+// uint16 values with non-zero unused high-order bytes. Generated code also
+// rejects maps whose keys are not sorted as marshalling writes them.
+//
+// ByteOrder, if set, replaces the big-endian byte order that XDR mandates
+// for integers, size prefixes included. This is not XDR anymore, but allows
+// reading formats that only differ from it in byte order. Padding and
+// alignment are unaffected, and 128-bit integers keep their high half first.
+//
+// MaxDepth limits how deeply structs and unions may be nested, so that a
+// crafted message for a recursive type, such as a linked list of optional
+// values, fails with ErrMaxDepth instead of exhausting the stack. Zero means
+// DefaultMaxDepth, and a negative value removes the limit.
+//
+// AllocBudget, if positive, caps the total number of bytes allocated for the
+// strings, copied byte slices and slices decoded from one message, so that a
+// message made of many fields within their max sizes still cannot make the
+// decoder allocate much more memory than it takes. Exceeding it fails with
+// ErrAllocBudget. Byte slices aliasing the buffer are free.
+//
+// Interner, if set, is used for the strings decoded, so that repeated values
+// share a single allocation; strings found in it are not charged to
+// AllocBudget. See StringInterner.
+//
+// When ZeroOnError is set, the data not yet unmarshalled is overwritten with
+// zeros as soon as an error occurs, so that sensitive values following the
+// failure point do not linger in the buffer. This is best effort: data
+// already consumed, slices returned aliasing it and copies made by the
+// caller or the runtime are left alone, so sensitive fields should be read
+// with the copying methods, such as UnmarshalBytesCopy. Errors must be set
+// through Fail for the zeroing to happen, as the generated code does.
+//
+// Hints, if set, gives the capacity the code generated by genxdr allocates
+// slices and maps with, so that decoding into the same value many times
+// reuses them rather than reallocating whenever a message holds more
+// elements than the last. See Hints.
+//
+// When Resumable is set, running out of data is reported with an error
+// wrapping ErrNeedMore rather than io.ErrUnexpectedEOF, for parsers that
+// receive their input in chunks: see Checkpoint and Resume.
 type Unmarshaller struct {
-	Error error
-	Data  []byte
+	Error       error
+	Data        []byte
+	Strict      bool
+	ByteOrder   binary.ByteOrder
+	MaxDepth    int
+	AllocBudget int
+	Interner    *StringInterner
+	ZeroOnError bool
+	Hints       Hints
+	Resumable   bool
+
+	offset    int
+	depth     int
+	allocated *int  // bytes charged to AllocBudget, shared with nested Unmarshallers
+	arrays    []int // elements left in each array being iterated, innermost last
+}
+
+// Hints maps fields, named as "Type.Field", to the number of elements their
+// slices and maps are expected to hold. Fields decoded with a smaller count
+// are allocated at the hinted capacity, and larger counts are allocated as
+// they are; the capacity allocated is charged to AllocBudget.
+type Hints map[string]int
+
+// Capacity returns the capacity to allocate for n elements of the named
+// field: n, or the Hints for the field if larger.
+func (u *Unmarshaller) Capacity(field string, n int) int {
+	if h := u.Hints[field]; h > n {
+		return h
+	}
+	return n
+}
+
+// DefaultMaxDepth is the nesting limit of an Unmarshaller with a zero
+// MaxDepth.
+const DefaultMaxDepth = 64
+
+// NewLimitedUnmarshaller reads exactly n bytes from r and returns an
+// Unmarshaller over them, so that decoding a framed message never reads past
+// the frame. As with io.ReadFull, the error is io.EOF if no bytes were read
+// and io.ErrUnexpectedEOF if the stream ended part way through. The caller is
+// responsible for bounding n, as the buffer is allocated up front.
+func NewLimitedUnmarshaller(r io.Reader, n int) (*Unmarshaller, error) {
+	if n < 0 {
+		return nil, ElementSizeExceeded("frame", n, 0)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+
+	return &Unmarshaller{Data: buf}, nil
+}
+
+// NewStrictUnmarshaller returns an Unmarshaller over data in strict mode,
+// after checking that its length is a multiple of four, as that of any XDR
+// data is. Truncated or corrupt input is thus rejected up front, with an error
+// wrapping ErrUnalignedData, rather than part way through decoding.
+func NewStrictUnmarshaller(data []byte) (*Unmarshaller, error) {
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrUnalignedData, len(data))
+	}
+
+	return &Unmarshaller{Data: data, Strict: true}, nil
+}
+
+// NewUnmarshallerFromReader reads r to its end and returns an Unmarshaller
+// over the data read.
+func NewUnmarshallerFromReader(r io.Reader) (*Unmarshaller, error) {
+	return NewUnmarshallerFromReaderMax(r, 0)
+}
+
+// NewUnmarshallerFromReaderMax is like NewUnmarshallerFromReader, but fails
+// with an ElementSizeExceeded error as soon as more than max bytes have been
+// read, so that a runaway reader cannot exhaust memory. A max of zero removes
+// the limit.
+func NewUnmarshallerFromReaderMax(r io.Reader, max int) (*Unmarshaller, error) {
+	if max <= 0 {
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return &Unmarshaller{Data: buf}, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r, int64(max)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > max {
+		return nil, ElementSizeExceeded("input", len(buf), max)
+	}
+	return &Unmarshaller{Data: buf}, nil
+}
+
+// Reset makes the Unmarshaller read from data and clears any previous
+// error, so that it can be reused for another message.
+func (u *Unmarshaller) Reset(data []byte) {
+	u.Data = data
+	u.Error = nil
+	u.offset = 0
+	u.depth = 0
+	u.allocated = nil
+	u.arrays = u.arrays[:0]
+}
+
+// Alloc charges n bytes, about to be allocated for a decoded value, to
+// AllocBudget and reports whether they fit in it. If they do not, Error is
+// set to ErrAllocBudget. The Unmarshal... methods that allocate call it, as
+// does the code generated by genxdr before allocating slices.
+func (u *Unmarshaller) Alloc(n int) bool {
+	if u.Error != nil {
+		return false
+	}
+	if u.AllocBudget <= 0 {
+		return true
+	}
+	if u.allocated == nil {
+		u.allocated = new(int)
+	}
+	if n < 0 || n > u.AllocBudget-*u.allocated {
+		u.Fail(ErrAllocBudget)
+		return false
+	}
+
+	*u.allocated += n
+	return true
+}
+
+// Fail sets Error to err, unless an error has already occurred, and returns
+// Error. With ZeroOnError, the data not yet unmarshalled is zeroed. The
+// Unmarshal... methods report their errors through it, as does the code
+// generated by genxdr.
+func (u *Unmarshaller) Fail(err error) error {
+	if u.Error != nil || err == nil {
+		return u.Error
+	}
+
+	u.Error = err
+	if u.ZeroOnError && !errors.Is(err, ErrNeedMore) {
+		for i := range u.Data {
+			u.Data[i] = 0
+		}
+	}
+	return err
+}
+
+// Enter records that a struct or union is about to be unmarshalled, and
+// reports whether it is within MaxDepth. If it is not, Error is set to
+// ErrMaxDepth. A successful Enter must be matched by a call to Leave once the
+// value has been unmarshalled; the code generated by genxdr does so.
+func (u *Unmarshaller) Enter() bool {
+	if u.Error != nil {
+		return false
+	}
+	max := u.MaxDepth
+	if max == 0 {
+		max = DefaultMaxDepth
+	}
+	if max > 0 && u.depth >= max {
+		u.Fail(ErrMaxDepth)
+		return false
+	}
+
+	u.depth++
+	return true
+}
+
+// Leave records that the value of the matching Enter has been unmarshalled.
+func (u *Unmarshaller) Leave() {
+	if u.depth > 0 {
+		u.depth--
+	}
+}
+
+// Clone returns a copy of the Unmarshaller at its current position, sharing
+// the buffer, so that data can be parsed speculatively. Unmarshalling from the
+// clone leaves u untouched; to keep the clone's progress, assign it back:
+//
+//	c := u.Clone()
+//	if err := v.UnmarshalXDRFrom(c); err == nil {
+//		*u = *c
+//	}
+func (u *Unmarshaller) Clone() *Unmarshaller {
+	c := *u
+	c.arrays = append([]int(nil), u.arrays...)
+	if u.allocated != nil {
+		allocated := *u.allocated
+		c.allocated = &allocated
+	}
+	return &c
+}
+
+// Checkpoint is the position of an Unmarshaller, saved by Checkpoint so that
+// unmarshalling can be resumed from it.
+type Checkpoint struct {
+	data      []byte
+	offset    int
+	depth     int
+	allocated int
+	arrays    []int
+}
+
+// Checkpoint returns the current position of the Unmarshaller, typically
+// taken before each value of a stream, for Resume to go back to.
+func (u *Unmarshaller) Checkpoint() Checkpoint {
+	cp := Checkpoint{data: u.Data, offset: u.offset, depth: u.depth}
+	if u.allocated != nil {
+		cp.allocated = *u.allocated
+	}
+	if len(u.arrays) > 0 {
+		cp.arrays = append([]int(nil), u.arrays...)
+	}
+	return cp
+}
+
+// Resume returns the Unmarshaller to the position saved in cp, clearing any
+// error reported since, and appends more to the data following it, so that
+// a value cut short by the end of the data can be unmarshalled again in
+// full. Data consumed before cp is dropped, so a long stream can be parsed
+// chunk by chunk with memory for a single value at most:
+//
+//	u := &xdr.Unmarshaller{Resumable: true}
+//	for {
+//		cp := u.Checkpoint()
+//		err := v.UnmarshalXDRFrom(u)
+//		if errors.Is(err, xdr.ErrNeedMore) {
+//			n, rerr := r.Read(chunk)
+//			// handle rerr
+//			u.Resume(cp, chunk[:n])
+//			continue
+//		}
+//		// handle err and v
+//	}
+//
+// Values unmarshalled since cp should be discarded, as they will be
+// unmarshalled again.
+func (u *Unmarshaller) Resume(cp Checkpoint, more []byte) {
+	// The data is copied, rather than appended to in place, as the spare
+	// capacity of the caller's buffer may hold anything.
+	u.Data = append(cp.data[:len(cp.data):len(cp.data)], more...)
+	u.Error = nil
+	u.offset = cp.offset
+	u.depth = cp.depth
+	if u.allocated != nil {
+		*u.allocated = cp.allocated
+	}
+	u.arrays = append(u.arrays[:0], cp.arrays...)
+}
+
+// Offset returns the number of bytes consumed from the buffer so far.
+func (u *Unmarshaller) Offset() int {
+	return u.offset
+}
+
+// Remaining returns the number of bytes left to be unmarshalled, or zero if
+// an error has occurred.
+func (u *Unmarshaller) Remaining() int {
+	if u.Error != nil {
+		return 0
+	}
+	return len(u.Data)
+}
+
+// Finish returns the error that stopped unmarshalling, if any, or an error
+// wrapping ErrTrailingData if the buffer was not consumed completely. Calling
+// it after the last field turns length mismatches between the data and the
+// schema into errors.
+func (u *Unmarshaller) Finish() error {
+	if u.Error != nil {
+		return u.Error
+	}
+	if len(u.Data) != 0 {
+		return fmt.Errorf("%w: %d bytes at offset %d", ErrTrailingData, len(u.Data), u.offset)
+	}
+	return nil
+}
+
+// Require checks that the buffer holds at least count elements of the given
+// encoded size, setting Error if it does not. This function is used by the
+// generated marshalling code to avoid allocating slices for element counts
+// the data cannot possibly contain.
+func (u *Unmarshaller) Require(count, size int) bool {
+	if u.Error != nil {
+		return false
+	}
+	if count < 0 || size > 0 && count > len(u.Data)/size {
+		u.unexpectedEOF()
+		return false
+	}
+	return true
 }
 
 // UnmarshalRaw returns a byte slice of length l from the buffer,
@@ -22,13 +361,72 @@ func (u *Unmarshaller) UnmarshalRaw(l int) []byte {
 	if u.Error != nil {
 		return nil
 	}
-	if len(u.Data) < l {
-		u.Error = io.ErrUnexpectedEOF
+	if l < 0 || len(u.Data) < l {
+		u.unexpectedEOF()
 		return nil
 	}
 
 	v := u.Data[:l]
-	u.Data = u.Data[l:]
+	u.advance(l)
+
+	return v
+}
+
+// UnmarshalRawPadded returns a byte slice of length l from the buffer,
+// without a size prefix, and consumes the padding following it to keep the
+// buffer aligned. In strict mode the padding must be zero.
+func (u *Unmarshaller) UnmarshalRawPadded(l int) []byte {
+	if u.Error != nil {
+		return nil
+	}
+	// Compare against what is left rather than adding to l, so that a huge l
+	// cannot overflow.
+	if l < 0 || l > len(u.Data) || len(u.Data)-l < Padding(l) {
+		u.unexpectedEOF()
+		return nil
+	}
+
+	if !u.checkPadding(u.Data[l : l+Padding(l)]) {
+		return nil
+	}
+
+	v := u.Data[:l]
+	u.advance(l + Padding(l))
+
+	return v
+}
+
+// UnmarshalFixedOpaque returns a byte slice of length n from the buffer,
+// without a size prefix, and skips the padding following it. This is the
+// encoding of XDR fixed-length opaque data.
+func (u *Unmarshaller) UnmarshalFixedOpaque(n int) []byte {
+	return u.UnmarshalRawPadded(n)
+}
+
+// Skip discards the next n bytes of the buffer. Unlike UnmarshalRaw, no
+// reference to the skipped bytes is returned.
+func (u *Unmarshaller) Skip(n int) {
+	if u.Error != nil {
+		return
+	}
+	if n < 0 || len(u.Data) < n {
+		u.unexpectedEOF()
+		return
+	}
+
+	u.advance(n)
+}
+
+// UnmarshalRemaining returns the rest of the buffer as raw bytes, without a
+// size prefix or padding, and leaves the buffer empty. This is suitable for
+// trailing payloads following a fixed header.
+func (u *Unmarshaller) UnmarshalRemaining() []byte {
+	if u.Error != nil {
+		return nil
+	}
+
+	v := u.Data
+	u.advance(len(v))
 
 	return v
 }
@@ -41,84 +439,222 @@ func (u *Unmarshaller) UnmarshalString() string {
 // UnmarshalStringMax returns a string up to a max length from the buffer.
 func (u *Unmarshaller) UnmarshalStringMax(max int) string {
 	buf := u.UnmarshalBytesMax(max)
-	if len(buf) == 0 || u.Error != nil {
+	if len(buf) == 0 {
+		return ""
+	}
+	if u.Interner != nil {
+		if s, ok := u.Interner.lookup(buf); ok {
+			return s
+		}
+	}
+	if !u.Alloc(len(buf)) {
 		return ""
 	}
 
+	if u.Interner != nil {
+		return u.Interner.add(string(buf))
+	}
 	return string(buf)
 }
 
-// UnmarshalBytes returns a byte slice from the buffer.
+// UnmarshalStringOK returns a string from the buffer, and whether a value
+// was present; that is, its length is not zero and no error has occurred.
+func (u *Unmarshaller) UnmarshalStringOK() (string, bool) {
+	s := u.UnmarshalStringMax(0)
+	return s, s != "" && u.Error == nil
+}
+
+// UnmarshalBytes returns a byte slice from the buffer. The returned slice
+// aliases the buffer; use UnmarshalBytesCopy to retain it independently.
 func (u *Unmarshaller) UnmarshalBytes() []byte {
 	return u.UnmarshalBytesMax(0)
 }
 
 // UnmarshalBytesMax returns a byte slice up to a max length from the buffer.
+// The returned slice aliases the buffer.
 func (u *Unmarshaller) UnmarshalBytesMax(max int) []byte {
 	if u.Error != nil {
 		return nil
 	}
 	if len(u.Data) < 4 {
-		u.Error = io.ErrUnexpectedEOF
+		u.unexpectedEOF()
 		return nil
 	}
 
-	l := int(u.Data[3]) | int(u.Data[2])<<8 | int(u.Data[1])<<16 | int(u.Data[0])<<24
+	l, err := CheckLength("bytes field", u.uint32(u.Data), max)
+	if err != nil {
+		u.Fail(err)
+		return nil
+	}
 	if l == 0 {
-		u.Data = u.Data[4:]
+		u.advance(4)
 		return nil
 	}
-	if l < 0 || max > 0 && l > max {
-		// l may be negative on 32 bit builds
-		u.Error = ElementSizeExceeded("bytes field", l, max)
+	// Compare against what is left rather than adding to l, so that a huge l
+	// cannot overflow whatever max is.
+	if l > len(u.Data)-4 || len(u.Data)-4-l < Padding(l) {
+		u.unexpectedEOF()
 		return nil
 	}
-	if len(u.Data) < l+4 {
-		u.Error = io.ErrUnexpectedEOF
+	if !u.checkPadding(u.Data[4+l : 4+l+Padding(l)]) {
 		return nil
 	}
 
 	v := u.Data[4 : 4+l]
-	u.Data = u.Data[4+l+Padding(l):]
+	u.advance(4 + l + Padding(l))
 
 	return v
 }
 
-// UnmarshalBool returns a bool from the buffer.
+// UnmarshalBytesN is like UnmarshalBytes, but also returns the number of
+// bytes consumed, that is the size prefix, the data and its padding, or zero
+// if an error occurred.
+func (u *Unmarshaller) UnmarshalBytesN() ([]byte, int) {
+	start := u.offset
+	v := u.UnmarshalBytes()
+	return v, u.offset - start
+}
+
+// UnmarshalNested returns an Unmarshaller over a message nested as
+// variable-length opaque data, as written by Marshaller.MarshalNested. The
+// nested message is consumed from u whether or not it is read, so unknown
+// messages can be skipped. Offsets within the returned Unmarshaller are
+// relative to the start of the nested message, whose data aliases the buffer.
+// If u has failed, the returned Unmarshaller carries the same error.
+func (u *Unmarshaller) UnmarshalNested() *Unmarshaller {
+	bs := u.UnmarshalBytes()
+	if u.AllocBudget > 0 && u.allocated == nil {
+		u.allocated = new(int)
+	}
+	return &Unmarshaller{Data: bs, Error: u.Error, Strict: u.Strict, ByteOrder: u.ByteOrder,
+		MaxDepth: u.MaxDepth, AllocBudget: u.AllocBudget, Interner: u.Interner, ZeroOnError: u.ZeroOnError,
+		Hints: u.Hints, depth: u.depth, allocated: u.allocated}
+}
+
+// UnmarshalUnion returns the discriminant of a discriminated union, as
+// written by Marshaller.MarshalUnion, from the buffer. The caller then
+// unmarshals the arm it selects. It returns zero if an error occurred, so the
+// error should be checked before switching on a discriminant of zero.
+func (u *Unmarshaller) UnmarshalUnion() int32 {
+	return u.UnmarshalInt32()
+}
+
+// UnmarshalBytesCopy returns a copy of a byte slice from the buffer.
+func (u *Unmarshaller) UnmarshalBytesCopy() []byte {
+	return u.UnmarshalBytesCopyMax(0)
+}
+
+// UnmarshalBytesCopyMax returns a copy of a byte slice up to a max length
+// from the buffer.
+func (u *Unmarshaller) UnmarshalBytesCopyMax(max int) []byte {
+	buf := u.UnmarshalBytesMax(max)
+	if len(buf) == 0 || !u.Alloc(len(buf)) {
+		return nil
+	}
+
+	return append([]byte(nil), buf...)
+}
+
+// UnmarshalBytesInto copies a byte slice from the buffer into dst and
+// returns the number of bytes copied. The value must fit in dst.
+func (u *Unmarshaller) UnmarshalBytesInto(dst []byte) int {
+	if u.Error != nil {
+		return 0
+	}
+	if len(u.Data) < 4 {
+		u.unexpectedEOF()
+		return 0
+	}
+
+	l, err := CheckLength("bytes field", u.uint32(u.Data), len(dst))
+	if err == nil && l > len(dst) {
+		// CheckLength takes an empty dst to mean no limit
+		err = ElementSizeExceeded("bytes field", l, len(dst))
+	}
+	if err != nil {
+		u.Fail(err)
+		return 0
+	}
+	if l == 0 {
+		u.advance(4)
+		return 0
+	}
+
+	return copy(dst, u.UnmarshalBytesMax(l))
+}
+
+// UnmarshalLength returns the size prefix of field, a string, opaque data or
+// a variable-length array, from the buffer. Lengths above max, if max is
+// positive, or above math.MaxInt32 fail with an ElementSizeError, the same on
+// 32 and 64 bit builds, as described by CheckLength. It returns zero if an
+// error occurred. This function is used by the generated marshalling code.
+func (u *Unmarshaller) UnmarshalLength(field string, max int) int {
+	v := u.UnmarshalUint32()
+	if u.Error != nil {
+		return 0
+	}
+	l, err := CheckLength(field, v, max)
+	if err != nil {
+		u.Fail(err)
+		return 0
+	}
+	return l
+}
+
+// UnmarshalBool returns a bool from the buffer. In strict mode, values other
+// than 0 and 1 are rejected.
 func (u *Unmarshaller) UnmarshalBool() bool {
-	return u.UnmarshalUint8() != 0
+	if !u.Strict {
+		return u.UnmarshalUint8() != 0
+	}
+
+	v := u.UnmarshalUint32()
+	if v > 1 {
+		u.Fail(ErrInvalidBool)
+		return false
+	}
+
+	return v == 1
 }
 
-// UnmarshalUint8 returns a uint8 from the buffer.
+// UnmarshalUint8 returns a uint8 from the buffer. In strict mode, the three
+// unused high-order bytes must be zero.
 func (u *Unmarshaller) UnmarshalUint8() uint8 {
 	if u.Error != nil {
 		return 0
 	}
 	if len(u.Data) < 4 {
-		u.Error = io.ErrUnexpectedEOF
+		u.unexpectedEOF()
 		return 0
 	}
+	v := u.uint32(u.Data)
+	if u.Strict && v > math.MaxUint8 {
+		u.Fail(ErrNonZeroHighBytes)
+		return 0
+	}
+	u.advance(4)
 
-	v := uint8(u.Data[3])
-	u.Data = u.Data[4:]
-
-	return v
+	return uint8(v)
 }
 
-// UnmarshalUint16 returns a uint16 from the buffer.
+// UnmarshalUint16 returns a uint16 from the buffer. In strict mode, the two
+// unused high-order bytes must be zero.
 func (u *Unmarshaller) UnmarshalUint16() uint16 {
 	if u.Error != nil {
 		return 0
 	}
 	if len(u.Data) < 4 {
-		u.Error = io.ErrUnexpectedEOF
+		u.unexpectedEOF()
 		return 0
 	}
+	v := u.uint32(u.Data)
+	if u.Strict && v > math.MaxUint16 {
+		u.Fail(ErrNonZeroHighBytes)
+		return 0
+	}
+	u.advance(4)
 
-	v := uint16(u.Data[3]) | uint16(u.Data[2])<<8
-	u.Data = u.Data[4:]
-
-	return v
+	return uint16(v)
 }
 
 // UnmarshalUint32 returns a uint32 from the buffer.
@@ -127,29 +663,401 @@ func (u *Unmarshaller) UnmarshalUint32() uint32 {
 		return 0
 	}
 	if len(u.Data) < 4 {
-		u.Error = io.ErrUnexpectedEOF
+		u.unexpectedEOF()
 		return 0
 	}
 
-	v := uint32(u.Data[3]) | uint32(u.Data[2])<<8 | uint32(u.Data[1])<<16 | uint32(u.Data[0])<<24
-	u.Data = u.Data[4:]
+	v := u.uint32(u.Data)
+	u.advance(4)
 
 	return v
 }
 
+// PeekUint32 returns the next uint32 from the buffer without consuming it,
+// so that a discriminant can be inspected before the value it precedes is
+// unmarshalled.
+func (u *Unmarshaller) PeekUint32() uint32 {
+	if u.Error != nil {
+		return 0
+	}
+	if len(u.Data) < 4 {
+		u.unexpectedEOF()
+		return 0
+	}
+
+	return u.uint32(u.Data)
+}
+
+// Uint32At returns the uint32 at offset bytes past the current position,
+// without consuming anything, for formats that locate their values through
+// an index. The offset is relative to the current position, that is to Data,
+// not to the start of the message: Uint32At(0) is PeekUint32. Data already
+// consumed cannot be read; to read at an absolute offset past the current
+// position, subtract Offset from it. Reading past the end of Data fails as
+// other short reads do, with ErrNeedMore in resumable mode.
+func (u *Unmarshaller) Uint32At(offset int) uint32 {
+	if u.Error != nil {
+		return 0
+	}
+	if offset < 0 {
+		// Consumed data is gone, so resuming with more would not help.
+		u.Fail(fmt.Errorf("%w at offset %d", io.ErrUnexpectedEOF, u.offset+offset))
+		return 0
+	}
+	if offset > len(u.Data)-4 {
+		u.unexpectedEOFAt(u.offset + offset)
+		return 0
+	}
+
+	return u.uint32(u.Data[offset:])
+}
+
 // UnmarshalUint64 returns a uint64 from the buffer.
 func (u *Unmarshaller) UnmarshalUint64() uint64 {
 	if u.Error != nil {
 		return 0
 	}
 	if len(u.Data) < 8 {
-		u.Error = io.ErrUnexpectedEOF
+		u.unexpectedEOF()
+		return 0
+	}
+
+	v := u.uint64(u.Data)
+	u.advance(8)
+
+	return v
+}
+
+// UnmarshalUint128 returns a 128-bit integer from the buffer, stored as 16
+// bytes in big-endian order. The high 64 bits are returned in v[0] and the
+// low ones in v[1].
+func (u *Unmarshaller) UnmarshalUint128() (v [2]uint64) {
+	if u.Error != nil {
+		return v
+	}
+	if len(u.Data) < 16 {
+		u.unexpectedEOF()
+		return v
+	}
+
+	v[0] = u.UnmarshalUint64()
+	v[1] = u.UnmarshalUint64()
+
+	return v
+}
+
+// UnmarshalUint32Slice returns a slice of uint32 from the buffer, with at
+// most max elements if max is positive.
+func (u *Unmarshaller) UnmarshalUint32Slice(max int) []uint32 {
+	l := u.unmarshalCount(max, 4)
+	if l == 0 {
+		return nil
+	}
+
+	if !u.Alloc(l * 4) {
+		return nil
+	}
+
+	vs := make([]uint32, l)
+	for i := range vs {
+		vs[i] = u.UnmarshalUint32()
+	}
+
+	return vs
+}
+
+// UnmarshalUint64Slice returns a slice of uint64 from the buffer, with at
+// most max elements if max is positive.
+func (u *Unmarshaller) UnmarshalUint64Slice(max int) []uint64 {
+	l := u.unmarshalCount(max, 8)
+	if l == 0 {
+		return nil
+	}
+
+	if !u.Alloc(l * 8) {
+		return nil
+	}
+
+	vs := make([]uint64, l)
+	for i := range vs {
+		vs[i] = u.UnmarshalUint64()
+	}
+
+	return vs
+}
+
+// UnmarshalBoolSlice returns a slice of bool from the buffer, with at most
+// max elements if max is positive.
+func (u *Unmarshaller) UnmarshalBoolSlice(max int) []bool {
+	l := u.unmarshalCount(max, 4)
+	if l == 0 {
+		return nil
+	}
+
+	if !u.Alloc(l * 1) {
+		return nil
+	}
+
+	vs := make([]bool, l)
+	for i := range vs {
+		vs[i] = u.UnmarshalBool()
+	}
+	if u.Error != nil {
+		return nil
+	}
+
+	return vs
+}
+
+// UnmarshalArray reads the element count of a variable-length array, with at
+// most max elements if max is positive, and starts iterating over it. This
+// allows processing large arrays one element at a time:
+//
+//	u.UnmarshalArray(max)
+//	for u.NextElement() {
+//		// unmarshal one element
+//	}
+//	u.EndArray()
+//
+// Arrays may be nested. The element count is returned for preallocation.
+func (u *Unmarshaller) UnmarshalArray(max int) int {
+	l := u.unmarshalCount(max, 4)
+	if u.Error != nil {
+		return 0
+	}
+
+	u.arrays = append(u.arrays, l)
+	return l
+}
+
+// NextElement reports whether another element of the array started by
+// UnmarshalArray is to be unmarshalled, and counts it as consumed.
+func (u *Unmarshaller) NextElement() bool {
+	if u.Error != nil {
+		return false
+	}
+	if len(u.arrays) == 0 {
+		u.Fail(ErrArrayMismatch)
+		return false
+	}
+
+	top := len(u.arrays) - 1
+	if u.arrays[top] == 0 {
+		return false
+	}
+	u.arrays[top]--
+	return true
+}
+
+// EndArray finishes the array started by UnmarshalArray, setting Error if
+// not all of its elements were consumed.
+func (u *Unmarshaller) EndArray() {
+	if u.Error != nil {
+		return
+	}
+	if len(u.arrays) == 0 || u.arrays[len(u.arrays)-1] != 0 {
+		u.Fail(ErrArrayMismatch)
+		return
+	}
+
+	u.arrays = u.arrays[:len(u.arrays)-1]
+}
+
+// UnmarshalInt32 returns an int32 from the buffer.
+func (u *Unmarshaller) UnmarshalInt32() int32 {
+	return int32(u.UnmarshalUint32())
+}
+
+// UnmarshalInt64 returns an int64 from the buffer.
+func (u *Unmarshaller) UnmarshalInt64() int64 {
+	return int64(u.UnmarshalUint64())
+}
+
+// UnmarshalEnum returns an int32 from the buffer, setting Error if it is not
+// one of the valid values, so that unknown enum values are rejected as they
+// are read. Large enums are better checked with UnmarshalEnumSet.
+func (u *Unmarshaller) UnmarshalEnum(valid ...int32) int32 {
+	v := u.UnmarshalInt32()
+	if u.Error != nil {
+		return 0
+	}
+	for _, e := range valid {
+		if v == e {
+			return v
+		}
+	}
+
+	u.Fail(InvalidEnumValue("enum", v))
+	return 0
+}
+
+// UnmarshalEnumSet is like UnmarshalEnum, with the valid values given as the
+// keys set to true in a map.
+func (u *Unmarshaller) UnmarshalEnumSet(valid map[int32]bool) int32 {
+	v := u.UnmarshalInt32()
+	if u.Error != nil {
+		return 0
+	}
+	if !valid[v] {
+		u.Fail(InvalidEnumValue("enum", v))
 		return 0
 	}
 
-	v := uint64(u.Data[7]) | uint64(u.Data[6])<<8 | uint64(u.Data[5])<<16 | uint64(u.Data[4])<<24 |
-		uint64(u.Data[3])<<32 | uint64(u.Data[2])<<40 | uint64(u.Data[1])<<48 | uint64(u.Data[0])<<56
-	u.Data = u.Data[8:]
+	return v
+}
+
+// UnmarshalTime returns an instant encoded by Marshaller.MarshalTime, in
+// UTC. Nanoseconds of a full second or more are rejected with ErrInvalidTime.
+func (u *Unmarshaller) UnmarshalTime() time.Time {
+	if u.Error != nil {
+		return time.Time{}
+	}
+	if len(u.Data) < 12 {
+		u.unexpectedEOF()
+		return time.Time{}
+	}
+
+	s := u.UnmarshalInt64()
+	ns := u.UnmarshalUint32()
+	if ns >= 1e9 {
+		u.Fail(ErrInvalidTime)
+		return time.Time{}
+	}
+
+	return time.Unix(s, int64(ns)).UTC()
+}
+
+// UnmarshalFloat16 returns an IEEE 754 half-precision value from the
+// buffer, stored like an uint16, as the float32 that represents it exactly.
+func (u *Unmarshaller) UnmarshalFloat16() float32 {
+	return float16ToFloat32(u.UnmarshalUint16())
+}
+
+// UnmarshalFloat32 returns a float32 from the buffer.
+func (u *Unmarshaller) UnmarshalFloat32() float32 {
+	return math.Float32frombits(u.UnmarshalUint32())
+}
 
+// UnmarshalFloat64 returns a float64 from the buffer.
+func (u *Unmarshaller) UnmarshalFloat64() float64 {
+	return math.Float64frombits(u.UnmarshalUint64())
+}
+
+// UnmarshalQuadRaw returns an XDR quadruple-precision float from the buffer
+// as its 16 bytes in big-endian order, uninterpreted, for passing through or
+// converting with an arbitrary precision library. ByteOrder does not apply.
+func (u *Unmarshaller) UnmarshalQuadRaw() (v [16]byte) {
+	copy(v[:], u.UnmarshalRaw(16))
 	return v
 }
+
+// TryUnmarshalBool returns a bool from the buffer along with u.Error, for
+// callers that check each value as it is read. An error left by an earlier
+// call is returned as well.
+func (u *Unmarshaller) TryUnmarshalBool() (bool, error) {
+	v := u.UnmarshalBool()
+	return v, u.Error
+}
+
+// TryUnmarshalUint32 returns a uint32 from the buffer along with u.Error.
+func (u *Unmarshaller) TryUnmarshalUint32() (uint32, error) {
+	v := u.UnmarshalUint32()
+	return v, u.Error
+}
+
+// TryUnmarshalUint64 returns a uint64 from the buffer along with u.Error.
+func (u *Unmarshaller) TryUnmarshalUint64() (uint64, error) {
+	v := u.UnmarshalUint64()
+	return v, u.Error
+}
+
+// TryUnmarshalInt32 returns an int32 from the buffer along with u.Error.
+func (u *Unmarshaller) TryUnmarshalInt32() (int32, error) {
+	v := u.UnmarshalInt32()
+	return v, u.Error
+}
+
+// TryUnmarshalInt64 returns an int64 from the buffer along with u.Error.
+func (u *Unmarshaller) TryUnmarshalInt64() (int64, error) {
+	v := u.UnmarshalInt64()
+	return v, u.Error
+}
+
+// TryUnmarshalStringMax returns a string up to a max length from the buffer
+// along with u.Error.
+func (u *Unmarshaller) TryUnmarshalStringMax(max int) (string, error) {
+	v := u.UnmarshalStringMax(max)
+	return v, u.Error
+}
+
+// TryUnmarshalBytesMax returns a byte slice up to a max length from the
+// buffer along with u.Error. The returned slice aliases the buffer.
+func (u *Unmarshaller) TryUnmarshalBytesMax(max int) ([]byte, error) {
+	v := u.UnmarshalBytesMax(max)
+	return v, u.Error
+}
+
+// advance consumes n bytes from the buffer.
+func (u *Unmarshaller) advance(n int) {
+	u.Data = u.Data[n:]
+	u.offset += n
+}
+
+// unmarshalCount reads the element count of a variable-length array of at
+// most max elements, if max is positive, each taking at least size bytes.
+// It returns zero if the count is zero or unacceptable.
+func (u *Unmarshaller) unmarshalCount(max, size int) int {
+	l := u.UnmarshalLength("slice field", max)
+	if l == 0 || !u.Require(l, size) {
+		return 0
+	}
+
+	return l
+}
+
+// uint32 decodes the uint32 at the start of b in the Unmarshaller's byte
+// order.
+func (u *Unmarshaller) uint32(b []byte) uint32 {
+	if u.ByteOrder != nil {
+		return u.ByteOrder.Uint32(b)
+	}
+	return Uint32BE(b)
+}
+
+// uint64 decodes the uint64 at the start of b in the Unmarshaller's byte
+// order.
+func (u *Unmarshaller) uint64(b []byte) uint64 {
+	if u.ByteOrder != nil {
+		return u.ByteOrder.Uint64(b)
+	}
+	return Uint64BE(b)
+}
+
+// checkPadding verifies, in strict mode, that all padding bytes are zero.
+func (u *Unmarshaller) checkPadding(pad []byte) bool {
+	if !u.Strict {
+		return true
+	}
+	for _, b := range pad {
+		if b != 0 {
+			u.Fail(ErrNonZeroPadding)
+			return false
+		}
+	}
+	return true
+}
+
+// unexpectedEOF records that the buffer ran out at the current offset.
+func (u *Unmarshaller) unexpectedEOF() {
+	u.unexpectedEOFAt(u.offset)
+}
+
+// unexpectedEOFAt records that the buffer ran out before the given offset,
+// with ErrNeedMore in resumable mode.
+func (u *Unmarshaller) unexpectedEOFAt(offset int) {
+	if u.Resumable {
+		u.Fail(fmt.Errorf("%w at offset %d", ErrNeedMore, offset))
+		return
+	}
+	u.Fail(fmt.Errorf("%w at offset %d", io.ErrUnexpectedEOF, offset))
+}